@@ -0,0 +1,185 @@
+package account
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	hezcommon "github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/common/apitypes"
+)
+
+const accountCreationAuthPath = "/v1/account-creation-authorization"
+
+var (
+	// ErrAccountCreationAuthNotFound is returned when the hermez node has no account creation authorization
+	// for the requested Ethereum address
+	ErrAccountCreationAuthNotFound = errors.New("account creation authorization not found")
+	// ErrAccountCreationAuthMissingSignature is returned when the BJJWallet has no account creation signature
+	ErrAccountCreationAuthMissingSignature = errors.New("account creation authorization signature is not set")
+	// ErrAccountCreationAuthInvalidSignature is returned when the account creation authorization signature
+	// was not produced by the Ethereum address for the given chain ID and rollup contract
+	ErrAccountCreationAuthInvalidSignature = errors.New("invalid account creation authorization signature")
+)
+
+// AccountCreationAuthError is returned when a hermez node refuses an account creation authorization request
+type AccountCreationAuthError struct {
+	StatusCode int    `json:"-"`
+	Message    string `json:"message"`
+	Code       int    `json:"code"`
+	Type       string `json:"type"`
+}
+
+func (e *AccountCreationAuthError) Error() string {
+	return fmt.Sprintf("hermez node returned HTTP %d: %s (code: %d - type: %s)", e.StatusCode, e.Message, e.Code, e.Type)
+}
+
+// Is makes errors.Is match ErrAccountCreationAuthNotFound and ErrAccountCreationAuthInvalidSignature
+func (e *AccountCreationAuthError) Is(target error) bool {
+	switch target {
+	case ErrAccountCreationAuthNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrAccountCreationAuthInvalidSignature:
+		return e.Type == "ErrInvalidSignature"
+	}
+	return false
+}
+
+// NewAccountCreation builds the account creation authorization request from a BJJWallet created with an account
+// creation signature (see CreateBjjWalletWithAccCreationSignatureFromHexPvtKey)
+func NewAccountCreation(bjjWallet BJJWallet) (accountCreation AccountCreation, err error) {
+	if len(bjjWallet.AccountCreationAuthSignature) < 1 {
+		err = fmt.Errorf("[Account][NewAccountCreation] Account: %s - Error: %w", bjjWallet.HezEthAddress, ErrAccountCreationAuthMissingSignature)
+		return
+	}
+	accountCreation.EthereumAddress = bjjWallet.HezEthAddress
+	accountCreation.HezBjjAddress = bjjWallet.HezBjjAddress
+	accountCreation.Signature = bjjWallet.AccountCreationAuthSignature
+	return
+}
+
+// SubmitAccountCreationAuth posts an account creation authorization to the current coordinator
+func SubmitAccountCreationAuth(hezClient client.HermezClient, accountCreation AccountCreation) (err error) {
+	if len(hezClient.CurrentCoordinatorURL) < 10 {
+		err = fmt.Errorf("[Account][SubmitAccountCreationAuth] Current Coordinator is not set : %s", hezClient.CurrentCoordinatorURL)
+		return
+	}
+	if err = hezClient.CheckSubmit(); err != nil {
+		err = fmt.Errorf("[Account][SubmitAccountCreationAuth] Account: %s - Error: %w", accountCreation.EthereumAddress, err)
+		return
+	}
+	req, err := hezClient.CurrentCoordinatorClient.New().Post(accountCreationAuthPath).BodyJSON(accountCreation).Request()
+	if err != nil {
+		err = fmt.Errorf("[Account][SubmitAccountCreationAuth] Error creating account creation authorization request: %w", err)
+		return
+	}
+	failureBody := AccountCreationAuthError{}
+	res, err := hezClient.CurrentCoordinatorClient.Do(req, nil, &failureBody)
+	if res != nil && res.StatusCode != http.StatusOK {
+		failureBody.StatusCode = res.StatusCode
+		log.Printf("[Account][SubmitAccountCreationAuth] Error posting account creation authorization. Account: %s - Error: %s\n", accountCreation.EthereumAddress, failureBody.Error())
+		err = &failureBody
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("[Account][SubmitAccountCreationAuth] Error posting account creation authorization to hermez node: %s - Error: %w", hezClient.CurrentCoordinatorURL, err)
+		return
+	}
+	return
+}
+
+// CreateAccountCreationAuth submits the account creation authorization built from the BJJWallet
+func CreateAccountCreationAuth(hezClient client.HermezClient, bjjWallet BJJWallet) (err error) {
+	accountCreation, err := NewAccountCreation(bjjWallet)
+	if err != nil {
+		return
+	}
+	return SubmitAccountCreationAuth(hezClient, accountCreation)
+}
+
+// GetAccountCreationAuth pulls from the current coordinator the account creation authorization of an Ethereum address
+func GetAccountCreationAuth(hezClient client.HermezClient, ethAddress string) (auth AccountCreationAuth, err error) {
+	if len(hezClient.CurrentCoordinatorURL) < 10 {
+		err = fmt.Errorf("[Account][GetAccountCreationAuth] Current Coordinator is not set : %s", hezClient.CurrentCoordinatorURL)
+		return
+	}
+	ethAddress = strings.TrimPrefix(ethAddress, "hez:")
+	if !common.IsHexAddress(ethAddress) {
+		err = fmt.Errorf("[Account][GetAccountCreationAuth] Invalid Ethereum address: %s", ethAddress)
+		return
+	}
+	url := accountCreationAuthPath + "/hez:" + common.HexToAddress(ethAddress).Hex()
+	req, err := hezClient.CurrentCoordinatorClient.New().Get(url).Request()
+	if err != nil {
+		err = fmt.Errorf("[Account][GetAccountCreationAuth] Error creating account creation authorization request: %w", err)
+		return
+	}
+	failureBody := AccountCreationAuthError{}
+	res, err := hezClient.CurrentCoordinatorClient.Do(req, &auth, &failureBody)
+	if res != nil && res.StatusCode != http.StatusOK {
+		failureBody.StatusCode = res.StatusCode
+		err = &failureBody
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("[Account][GetAccountCreationAuth] Error pulling account creation authorization from hermez node: %s - Error: %w", hezClient.CurrentCoordinatorURL, err)
+		return
+	}
+	return
+}
+
+// HasAccountCreationAuth checks if the current coordinator has an account creation authorization to an Ethereum address
+func HasAccountCreationAuth(hezClient client.HermezClient, ethAddress string) (bool, error) {
+	_, err := GetAccountCreationAuth(hezClient, ethAddress)
+	if errors.Is(err, ErrAccountCreationAuthNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// VerifyAccountCreationAuth checks locally that the authorization signature was done by its Ethereum address for
+// the given chain ID and rollup contract
+func VerifyAccountCreationAuth(auth AccountCreationAuth, chainID int, rollupContractAddress string) (err error) {
+	if chainID > 65535 || chainID < 0 {
+		err = fmt.Errorf("[Account][VerifyAccountCreationAuth] Invalid chainID: %d", chainID)
+		return
+	}
+	if !common.IsHexAddress(rollupContractAddress) {
+		err = fmt.Errorf("[Account][VerifyAccountCreationAuth] Invalid rollup contract address: %s", rollupContractAddress)
+		return
+	}
+	ethAddr, err := apitypes.HezEthAddr(auth.EthereumAddress).ToEthAddr()
+	if err != nil {
+		err = fmt.Errorf("[Account][VerifyAccountCreationAuth] Invalid Ethereum address: %s - Error: %s", auth.EthereumAddress, err.Error())
+		return
+	}
+	bjj, err := apitypes.HezBJJ(auth.HezBjjAddress).ToBJJ()
+	if err != nil {
+		err = fmt.Errorf("[Account][VerifyAccountCreationAuth] Invalid BJJ address: %s - Error: %s", auth.HezBjjAddress, err.Error())
+		return
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(auth.Signature, "0x"))
+	if err != nil || len(signature) != 65 {
+		err = fmt.Errorf("[Account][VerifyAccountCreationAuth] Account: %s - Error: %w", auth.EthereumAddress, ErrAccountCreationAuthInvalidSignature)
+		return
+	}
+	commonAuth := hezcommon.AccountCreationAuth{
+		EthAddr:   ethAddr,
+		BJJ:       bjj,
+		Signature: signature,
+	}
+	valid, err := commonAuth.VerifySignature(uint16(chainID), common.HexToAddress(rollupContractAddress))
+	if !valid || err != nil {
+		err = fmt.Errorf("[Account][VerifyAccountCreationAuth] Account: %s - Error: %w", auth.EthereumAddress, ErrAccountCreationAuthInvalidSignature)
+		return
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package account
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+)
+
+func TestSubmitAccountCreationAuthRunsSubmitCheck(t *testing.T) {
+	var posts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == accountCreationAuthPath {
+			atomic.AddInt32(&posts, 1)
+		}
+	}))
+	defer srv.Close()
+	var hezClient client.HermezClient
+	hezClient.SetCurrentCoordinator(srv.URL)
+
+	accountCreation, err := NewAccountCreation(newTestWallet(t))
+	if err != nil {
+		t.Fatalf("NewAccountCreation: %s", err)
+	}
+	errLagging := errors.New("node is lagging")
+	hezClient.SubmitCheck = func(client.HermezClient) error { return errLagging }
+	if err = SubmitAccountCreationAuth(hezClient, accountCreation); !errors.Is(err, errLagging) {
+		t.Errorf("SubmitAccountCreationAuth with a failing SubmitCheck: %v", err)
+	}
+	if atomic.LoadInt32(&posts) != 0 {
+		t.Fatal("the authorization was posted although SubmitCheck failed")
+	}
+
+	hezClient.SubmitCheck = func(client.HermezClient) error { return nil }
+	if err = SubmitAccountCreationAuth(hezClient, accountCreation); err != nil {
+		t.Fatalf("SubmitAccountCreationAuth: %s", err)
+	}
+	if atomic.LoadInt32(&posts) != 1 {
+		t.Errorf("%d authorizations posted, expected 1", posts)
+	}
+}
@@ -0,0 +1,139 @@
+package account
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethMath "github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	ethSigner "github.com/ethereum/go-ethereum/signer/core"
+	hezcommon "github.com/hermeznetwork/hermez-node/common"
+)
+
+// ErrSignerMismatch is returned when a signature was not produced by the expected Ethereum address
+var ErrSignerMismatch = errors.New("signature was not produced by the expected Ethereum address")
+
+// HermezWalletMessage returns the exact message an external Ethereum wallet must sign with personal_sign
+// (EIP-191) to generate the BJJWallet
+func HermezWalletMessage() string {
+	return hermezWalletMsg
+}
+
+// AccountCreationAuthTypedData returns the EIP-712 typed data an external Ethereum wallet must sign with
+// eth_signTypedData_v4 to authorize the creation of Hermez accounts to the BJJWallet
+func AccountCreationAuthTypedData(bjjWallet BJJWallet, chainID int, rollupContractAddress string) (typedData ethSigner.TypedData, err error) {
+	if chainID > 65535 || chainID < 1 {
+		err = fmt.Errorf("[AccountCreationAuthTypedData] Invalid chainID: %d", chainID)
+		return
+	}
+	if !common.IsHexAddress(rollupContractAddress) {
+		err = fmt.Errorf("[AccountCreationAuthTypedData] Invalid rollup contract address: %s", rollupContractAddress)
+		return
+	}
+	bjjPubKeyComp := bjjWallet.BJJPublicKey()
+	typedData = ethSigner.TypedData{
+		Types: ethSigner.Types{
+			"EIP712Domain": []ethSigner.Type{
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Authorise": []ethSigner.Type{
+				{Name: "Provider", Type: "string"},
+				{Name: "Authorisation", Type: "string"},
+				{Name: "BJJKey", Type: "bytes32"},
+			},
+		},
+		PrimaryType: "Authorise",
+		Domain: ethSigner.TypedDataDomain{
+			Name:              hezcommon.EIP712Provider,
+			Version:           hezcommon.EIP712Version,
+			ChainId:           ethMath.NewHexOrDecimal256(int64(chainID)),
+			VerifyingContract: common.HexToAddress(rollupContractAddress).Hex(),
+		},
+		Message: ethSigner.TypedDataMessage{
+			"Provider":      hezcommon.EIP712Provider,
+			"Authorisation": hezcommon.AccountCreationAuthMsg,
+			"BJJKey":        hexutil.Encode(hezcommon.SwapEndianness(bjjPubKeyComp[:])),
+		},
+	}
+	return
+}
+
+// CreateBJJWalletFromExternalSignatures builds the BJJWallet of an external Ethereum wallet from the signature of
+// HermezWalletMessage and, when chainID and rollupContractAddress are set, the signature of
+// AccountCreationAuthTypedData. Both signatures must be produced by ethAddress, otherwise ErrSignerMismatch is returned.
+func CreateBJJWalletFromExternalSignatures(ethAddress string, signedMsg []byte, accountCreationSignature []byte, chainID int, rollupContractAddress string) (bjjWallet BJJWallet, err error) {
+	if !common.IsHexAddress(ethAddress) {
+		err = fmt.Errorf("[CreateBJJWalletFromExternalSignatures] Invalid Ethereum address: %s", ethAddress)
+		return
+	}
+	bjjWallet, ethAccount, err := CreateBJJWalletFromSignedMsg(signedMsg)
+	if err != nil {
+		err = fmt.Errorf("[CreateBJJWalletFromExternalSignatures] Error generating BJJ Wallet - Error: %w", err)
+		return
+	}
+	if ethAccount.Address != common.HexToAddress(ethAddress) {
+		err = fmt.Errorf("[CreateBJJWalletFromExternalSignatures] Expected: %s - Recovered: %s - Error: %w", ethAddress, ethAccount.Address.Hex(), ErrSignerMismatch)
+		return BJJWallet{}, err
+	}
+	if chainID == 0 || !common.IsHexAddress(rollupContractAddress) {
+		return
+	}
+
+	signature := normalizeEthSignature(accountCreationSignature, 27)
+	if signature == nil {
+		err = fmt.Errorf("[CreateBJJWalletFromExternalSignatures] Account: %s - Error: %w", ethAddress, ErrAccountCreationAuthInvalidSignature)
+		return BJJWallet{}, err
+	}
+	auth := AccountCreationAuth{
+		EthereumAddress: bjjWallet.HezEthAddress,
+		HezBjjAddress:   bjjWallet.HezBjjAddress,
+		Signature:       hex.EncodeToString(signature),
+	}
+	if err = VerifyAccountCreationAuth(auth, chainID, rollupContractAddress); err != nil {
+		err = fmt.Errorf("[CreateBJJWalletFromExternalSignatures] Error: %w", err)
+		return BJJWallet{}, err
+	}
+	bjjWallet.AccountCreationAuthSignature = auth.Signature
+	return
+}
+
+// recoverHermezWalletMsgSigner returns the Ethereum account that signed the hermez standard message
+func recoverHermezWalletMsgSigner(signedMsg []byte) (ethAccount accounts.Account, err error) {
+	signature := normalizeEthSignature(signedMsg, 0)
+	if signature == nil {
+		err = fmt.Errorf("invalid signature length: %d", len(signedMsg))
+		return
+	}
+	publicKey, err := crypto.SigToPub(accounts.TextHash([]byte(hermezWalletMsg)), signature)
+	if err != nil {
+		return
+	}
+	ethAccount.Address = crypto.PubkeyToAddress(*publicKey)
+	return
+}
+
+// normalizeEthSignature returns a copy of the [R || S || V] signature with V as vBase or vBase+1, accepting V as
+// 0/1 or 27/28. It returns nil when the signature is malformed
+func normalizeEthSignature(signature []byte, vBase byte) []byte {
+	if len(signature) != crypto.SignatureLength {
+		return nil
+	}
+	normalized := make([]byte, len(signature))
+	copy(normalized, signature)
+	v := normalized[crypto.RecoveryIDOffset]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return nil
+	}
+	normalized[crypto.RecoveryIDOffset] = v + vBase
+	return normalized
+}
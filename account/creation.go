@@ -13,7 +13,6 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	hezcommon "github.com/hermeznetwork/hermez-node/common"
 	"github.com/iden3/go-iden3-crypto/babyjub"
-	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
 )
 
 const (
@@ -21,12 +20,20 @@ const (
 	hermezWalletMsg   = "Hermez Network account access.\n\nSign this message if you are in a trusted application only."
 )
 
-// CreateBJJWalletFromSignedMsg creates BJJWallet from signed hermez standard message to generate account
+// CreateBJJWalletFromSignedMsg creates BJJWallet from signed hermez standard message to generate account. The signature
+// V can be 0/1 or 27/28 and the Ethereum account that signed the message is recovered from it. signedMsg is not modified.
 func CreateBJJWalletFromSignedMsg(signedMsg []byte) (bjjWallet BJJWallet, ethAccount accounts.Account, err error) {
-	// Change the last item value of the byte array from 1 to 28
-	signedMsg[len(signedMsg)-1] += 27
+	ethAccount, err = recoverHermezWalletMsgSigner(signedMsg)
+	if err != nil {
+		err = fmt.Errorf("[CreateBJJWalletFromSignedMsg] Error recovering signer of hermez message - Error: %s\n", err.Error())
+		log.Println(err.Error())
+		return
+	}
+
+	// Use a copy with the last item value of the byte array changed from 0/1 to 27/28
+	hermezWalletMsgSigned := normalizeEthSignature(signedMsg, 27)
 
-	hermezWalletMsgEncoded := "0x" + hex.EncodeToString(signedMsg)
+	hermezWalletMsgEncoded := "0x" + hex.EncodeToString(hermezWalletMsgSigned)
 	// log.Println("hermezWalletMsgEncoded: ", hermezWalletMsgEncoded)
 	hermezWalletMsgSignedHash := crypto.Keccak256([]byte(hermezWalletMsgEncoded))
 
@@ -34,66 +41,19 @@ func CreateBJJWalletFromSignedMsg(signedMsg []byte) (bjjWallet BJJWallet, ethAcc
 	// Copy to the private key
 	copy(bjjPvtKey[:], hermezWalletMsgSignedHash[:])
 
-	bjjAddress, err := FromBJJPubKeyCompToHezBJJAddress(bjjPvtKey.Public().Compress())
+	bjjWallet, err = newBJJWallet(bjjPvtKey, ethAccount)
 	if err != nil {
-		err = fmt.Errorf("[CreateBJJWalletFromSignedMsg] Error generating BJJ address from BJJ public key. Account: %+v - Error: %s\n", bjjPvtKey.Public().Compress(), err.Error())
+		err = fmt.Errorf("[CreateBJJWalletFromSignedMsg] Error generating BJJ Wallet - Error: %s\n", err.Error())
 		log.Println(err.Error())
 		return
 	}
 
-	decodedBjjPubKey, err := hex.DecodeString(bjjPvtKey.Public().Compress().String())
-	if err != nil {
-		err = fmt.Errorf("[CreateBJJWalletFromSignedMsg] Error decoding BJJ public key. Account: %+v - Error: %s\n", bjjPvtKey, err.Error())
-		log.Println(err.Error())
-		return
-	}
-
-	var bjjPubKeyCompressed babyjub.PublicKeyComp
-	temp := hezcommon.SwapEndianness(decodedBjjPubKey)
-	copy(bjjPubKeyCompressed[:], temp[:])
-
-	bjjWallet.PrivateKey = bjjPvtKey
-	bjjWallet.PublicKey = bjjPubKeyCompressed
-	bjjWallet.HezBjjAddress = bjjAddress
-	bjjWallet.EthAccount = ethAccount
-	bjjWallet.HezEthAddress = "hez:" + ethAccount.Address.Hex()
-
 	return
 }
 
-// CreateBjjWalletFromMnemonic Create a Babyjubjub Wallet from Mnemonic
+// CreateBjjWalletFromMnemonic Create a Babyjubjub Wallet from Mnemonic using the first derivation index
 func CreateBjjWalletFromMnemonic(mnemonic string) (bjjWallet BJJWallet, ethAccount accounts.Account, err error) {
-	ethWallet, err := hdwallet.NewFromMnemonic(mnemonic)
-	if err != nil {
-		err = fmt.Errorf("[CreateBjjWalletFromMnemonic] Error creating ethereum account from mnemonic - Error: %s\n", err.Error())
-		log.Println(err.Error())
-		return
-	}
-
-	// Generate ETH account
-	path := hdwallet.MustParseDerivationPath(fmt.Sprintf(ethDerivationPath, 0))
-	ethAccount, err = ethWallet.Derive(path, true)
-	if err != nil {
-		err = fmt.Errorf("[CreateBjjWalletFromMnemonic] Error deriving the account from mnemonic Error: %s\n", err.Error())
-		log.Println(err.Error())
-		return
-	}
-
-	hermezWalletMsgSigned, err := ethWallet.SignText(ethAccount, []byte(hermezWalletMsg))
-	if err != nil {
-		err = fmt.Errorf("[CreateBjjWalletFromMnemonic] Error signing key msg to generate BJJ private key. Account: %s - Error: %s\n", ethAccount.Address.Hex(), err.Error())
-		log.Println(err.Error())
-		return
-	}
-
-	bjjWallet, ethAccount, err = CreateBJJWalletFromSignedMsg(hermezWalletMsgSigned)
-	if err != nil {
-		err = fmt.Errorf("[CreateBjjWalletFromMnemonic] Error generating BJJ Wallet. Account: %s - Error: %s\n", ethAccount.Address.Hex(), err.Error())
-		log.Println(err.Error())
-		return
-	}
-
-	return
+	return CreateBjjWalletFromMnemonicWithIndex(mnemonic, 0)
 }
 
 // CreateBjjWalletFromHexPvtKey Create a Babyjubjub Wallet from Hexdecimal Private Key
@@ -116,8 +76,23 @@ func CreateBjjWalletWithAccCreationSignatureFromPvtKey(ecdsaPvtKey *ecdsa.Privat
 	ecdsaPubKey := ecdsaPvtKey.Public().(*ecdsa.PublicKey)
 	ethAccount.Address = crypto.PubkeyToAddress(*ecdsaPubKey)
 
+	bjjWallet, err = createBjjWalletWithSignHash(ethAccount, func(hash []byte) ([]byte, error) {
+		return crypto.Sign(hash, ecdsaPvtKey)
+	}, chainID, rollupContractAddress)
+	if err != nil {
+		return
+	}
+	// keep a copy, so BJJWallet.Destroy doesn't zero the caller's key
+	bjjWallet.ethPrivateKey = crypto.ToECDSAUnsafe(crypto.FromECDSA(ecdsaPvtKey))
+	return
+}
+
+// createBjjWalletWithSignHash generates the BJJWallet of an Ethereum account signing the hermez standard message
+// with signHash, and the hermez account creation signature when chainID and rollupContractAddress are set.
+// signHash must return a [R || S || V] signature with V as 0 or 1, like crypto.Sign.
+func createBjjWalletWithSignHash(ethAccount accounts.Account, signHash func(hash []byte) ([]byte, error), chainID int, rollupContractAddress string) (bjjWallet BJJWallet, err error) {
 	hermezWalletMsgHash := accounts.TextHash([]byte(hermezWalletMsg))
-	hermezWalletMsgSigned, err := crypto.Sign(hermezWalletMsgHash, ecdsaPvtKey)
+	hermezWalletMsgSigned, err := signHash(hermezWalletMsgHash)
 	if err != nil {
 		log.Printf("[CreateBjjWalletFromHexPvtKey] Error signing key msg to generate BJJ private key. Account: %s - Error: %s\n", ethAccount.Address.Hex(), err.Error())
 		return
@@ -134,15 +109,38 @@ func CreateBjjWalletWithAccCreationSignatureFromPvtKey(ecdsaPvtKey *ecdsa.Privat
 	// Copy to the private key
 	copy(bjjPvtKey[:], hermezWalletMsgSignedHash[:])
 
+	bjjWallet, err = newBJJWallet(bjjPvtKey, ethAccount)
+	if err != nil {
+		log.Printf("[CreateBjjWalletFromHexPvtKey] Error generating BJJ Wallet. Account: %s - Error: %s\n", ethAccount.Address.Hex(), err.Error())
+		return
+	}
+
+	if chainID == 0 || !common.IsHexAddress(rollupContractAddress) {
+		return
+	}
+
+	rollupAddress := common.HexToAddress(rollupContractAddress)
+	signature, err := createHermezAuthSignatureWithSignHash(signHash, ethAccount, bjjPvtKey.Public().Compress(), chainID, rollupAddress)
+	if err != nil {
+		log.Printf("[CreateBjjWalletFromHexPvtKey] Error creating CreateHermezAuthSignature: %+v - %d - %s - Error: %s\n", bjjWallet.PublicKey, chainID, rollupAddress, err.Error())
+		return
+	}
+	bjjWallet.AccountCreationAuthSignature = signature
+
+	return
+}
+
+// newBJJWallet fills the BJJWallet fields from the BJJ private key and the Ethereum account that generated it
+func newBJJWallet(bjjPvtKey babyjub.PrivateKey, ethAccount accounts.Account) (bjjWallet BJJWallet, err error) {
 	bjjAddress, err := FromBJJPubKeyCompToHezBJJAddress(bjjPvtKey.Public().Compress())
 	if err != nil {
-		log.Printf("[CreateBjjWalletFromHexPvtKey] Error generating BJJ address from BJJ public key. Account: %+v - Error: %s\n", bjjPvtKey.Public().Compress(), err.Error())
+		err = fmt.Errorf("[newBJJWallet] Error generating BJJ address from BJJ public key. Account: %+v - Error: %s\n", bjjPvtKey.Public().Compress(), err.Error())
 		return
 	}
 
 	decodedBjjPubKey, err := hex.DecodeString(bjjPvtKey.Public().Compress().String())
 	if err != nil {
-		log.Printf("[CreateBjjWalletFromHexPvtKey] Error decoding BJJ public key. Account: %+v - Error: %s\n", bjjPvtKey, err.Error())
+		err = fmt.Errorf("[newBJJWallet] Error decoding BJJ public key. Account: %s - Error: %s\n", bjjAddress, err.Error())
 		return
 	}
 
@@ -150,26 +148,11 @@ func CreateBjjWalletWithAccCreationSignatureFromPvtKey(ecdsaPvtKey *ecdsa.Privat
 	temp := hezcommon.SwapEndianness(decodedBjjPubKey)
 	copy(bjjPubKeyCompressed[:], temp[:])
 
-	bjjWallet.PrivateKey = bjjPvtKey
+	bjjWallet.privateKey = &bjjPvtKey
 	bjjWallet.PublicKey = bjjPubKeyCompressed
 	bjjWallet.HezBjjAddress = bjjAddress
 	bjjWallet.EthAccount = ethAccount
 	bjjWallet.HezEthAddress = "hez:" + ethAccount.Address.Hex()
-
-	if chainID == 0 || !common.IsHexAddress(rollupContractAddress) {
-		return
-	}
-
-	var bjjPubKeyCompressedNoSwapped babyjub.PublicKeyComp
-	copy(bjjPubKeyCompressedNoSwapped[:], decodedBjjPubKey[:])
-	rollupAddress := common.HexToAddress(rollupContractAddress)
-	signature, err := CreateHermezAuthSignature(ecdsaPvtKey, ethAccount, bjjPubKeyCompressedNoSwapped, chainID, rollupAddress)
-	if err != nil {
-		log.Printf("[CreateBjjWalletFromHexPvtKey] Error creating CreateHermezAuthSignature: %+v - %d - %s - Error: %s\n", bjjPubKeyCompressed, chainID, rollupAddress, err.Error())
-		return
-	}
-	bjjWallet.AccountCreationAuthSignature = signature
-
 	return
 }
 
@@ -188,6 +171,14 @@ func FromBJJPubKeyCompToHezBJJAddress(pkComp babyjub.PublicKeyComp) (string, err
 
 // CreateHermezAuthSignature creates the hermez wallet authentication signature
 func CreateHermezAuthSignature(ethPk *ecdsa.PrivateKey, ethAccount accounts.Account, bjjPubKeyComp babyjub.PublicKeyComp, chainID int, rollupContract common.Address) (string, error) {
+	return createHermezAuthSignatureWithSignHash(func(hash []byte) ([]byte, error) {
+		return crypto.Sign(hash, ethPk)
+	}, ethAccount, bjjPubKeyComp, chainID, rollupContract)
+}
+
+// createHermezAuthSignatureWithSignHash creates the hermez wallet authentication signature using signHash to sign
+// the EIP-712 hash with the Ethereum account
+func createHermezAuthSignatureWithSignHash(signHash func(hash []byte) ([]byte, error), ethAccount accounts.Account, bjjPubKeyComp babyjub.PublicKeyComp, chainID int, rollupContract common.Address) (string, error) {
 	auth := &hezcommon.AccountCreationAuth{
 		EthAddr: ethAccount.Address,
 		BJJ:     bjjPubKeyComp,
@@ -199,9 +190,7 @@ func CreateHermezAuthSignature(ethPk *ecdsa.PrivateKey, ethAccount accounts.Acco
 	}
 	uChainID := uint16(chainID)
 
-	err := auth.Sign(func(hash []byte) ([]byte, error) {
-		return crypto.Sign(hash, ethPk)
-	}, uChainID, rollupContract)
+	err := auth.Sign(signHash, uChainID, rollupContract)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,85 @@
+package account
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
+)
+
+// DefaultDiscoveryGapLimit is the number of consecutive unused derivation indices after which the discovery stops
+const DefaultDiscoveryGapLimit = 20
+
+// DiscoveredWallet is a wallet derived from a mnemonic that owns at least one Hermez account
+type DiscoveredWallet struct {
+	DerivationIndex int
+	BjjWallet       BJJWallet
+	Accounts        []Account
+}
+
+// DiscoverAccounts derives wallets from the mnemonic sequentially and pulls the accounts of both its hez Ethereum
+// address and hez BJJ address. The scan stops after gapLimit consecutive derivation indices without accounts.
+func DiscoverAccounts(hezClient client.HermezClient, mnemonic string, gapLimit int) (discovered []DiscoveredWallet, err error) {
+	if gapLimit < 1 {
+		err = fmt.Errorf("[Account][DiscoverAccounts] Invalid gap limit: %d", gapLimit)
+		return
+	}
+	ethWallet, err := hdwallet.NewFromMnemonic(mnemonic)
+	if err != nil {
+		err = fmt.Errorf("[Account][DiscoverAccounts] Error creating ethereum account from mnemonic - Error: %s\n", err.Error())
+		return
+	}
+
+	gap := 0
+	for index := 0; gap < gapLimit; index++ {
+		path := hdwallet.MustParseDerivationPath(fmt.Sprintf(ethDerivationPath, index))
+		var bjjWallet BJJWallet
+		bjjWallet, _, err = createBjjWalletFromHDWallet(ethWallet, path, 0, "")
+		if err != nil {
+			err = fmt.Errorf("[Account][DiscoverAccounts] Error generating BJJ Wallet at index %d - Error: %s\n", index, err.Error())
+			return
+		}
+
+		var accounts []Account
+		accounts, err = discoverWalletAccounts(hezClient, bjjWallet)
+		if err != nil {
+			err = fmt.Errorf("[Account][DiscoverAccounts] Error pulling accounts at index %d - Error: %s\n", index, err.Error())
+			return
+		}
+		if len(accounts) < 1 {
+			gap++
+			continue
+		}
+		log.Printf("[Account][DiscoverAccounts] Found %d accounts at index %d: %s\n", len(accounts), index, bjjWallet.HezEthAddress)
+		gap = 0
+		discovered = append(discovered, DiscoveredWallet{
+			DerivationIndex: index,
+			BjjWallet:       bjjWallet,
+			Accounts:        accounts,
+		})
+	}
+	return
+}
+
+// discoverWalletAccounts pulls the accounts owned by the hez Ethereum address and the hez BJJ address of the
+// wallet, without duplicates
+func discoverWalletAccounts(hezClient client.HermezClient, bjjWallet BJJWallet) (accounts []Account, err error) {
+	ethAccounts, err := GetAllAccountsInfo(hezClient, bjjWallet.EthAccount.Address.Hex())
+	if err != nil {
+		return
+	}
+	bjjAccounts, err := GetAllAccountsInfo(hezClient, bjjWallet.HezBjjAddress)
+	if err != nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, acc := range append(ethAccounts, bjjAccounts...) {
+		if seen[acc.AccountIndex] {
+			continue
+		}
+		seen[acc.AccountIndex] = true
+		accounts = append(accounts, acc)
+	}
+	return
+}
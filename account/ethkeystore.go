@@ -0,0 +1,77 @@
+package account
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CreateBjjWalletFromEthKeyStoreJSON Create a Babyjubjub Wallet from an Ethereum V3 keystore JSON, with hermez account
+// creation signature when chainID and rollupContractAddress are set. The decrypted key is zeroed once the wallet, which
+// keeps its own copy, is created.
+func CreateBjjWalletFromEthKeyStoreJSON(keyJSON []byte, passphrase string, chainID int, rollupContractAddress string) (bjjWallet BJJWallet, ethAccount accounts.Account, err error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		err = fmt.Errorf("[CreateBjjWalletFromEthKeyStoreJSON] Error decrypting Ethereum keystore - Error: %w", err)
+		return
+	}
+	defer zeroEthPrivateKey(key.PrivateKey)
+	return CreateBjjWalletWithAccCreationSignatureFromPvtKey(key.PrivateKey, chainID, rollupContractAddress)
+}
+
+// CreateBjjWalletFromEthKeyStoreFile Create a Babyjubjub Wallet from an Ethereum V3 keystore file, with hermez account
+// creation signature when chainID and rollupContractAddress are set
+func CreateBjjWalletFromEthKeyStoreFile(path string, passphrase string, chainID int, rollupContractAddress string) (bjjWallet BJJWallet, ethAccount accounts.Account, err error) {
+	keyJSON, err := ioutil.ReadFile(path)
+	if err != nil {
+		err = fmt.Errorf("[CreateBjjWalletFromEthKeyStoreFile] Error reading Ethereum keystore file: %s - Error: %s", path, err.Error())
+		return
+	}
+	return CreateBjjWalletFromEthKeyStoreJSON(keyJSON, passphrase, chainID, rollupContractAddress)
+}
+
+// CreateBjjWalletFromEthKeyStore Create a Babyjubjub Wallet from an account of a go-ethereum keystore, with hermez
+// account creation signature when chainID and rollupContractAddress are set. The messages are signed by the keystore,
+// so the Ethereum private key never leaves it and the returned BJJWallet doesn't hold it.
+func CreateBjjWalletFromEthKeyStore(ks *keystore.KeyStore, ethAccount accounts.Account, passphrase string, chainID int, rollupContractAddress string) (bjjWallet BJJWallet, err error) {
+	if !ks.HasAddress(ethAccount.Address) {
+		err = fmt.Errorf("[CreateBjjWalletFromEthKeyStore] Account %s not found in the keystore", ethAccount.Address.Hex())
+		return
+	}
+	bjjWallet, err = CreateBjjWalletFromEthSigner(NewKeyStoreEthSigner(ks, ethAccount, passphrase), chainID, rollupContractAddress)
+	if err != nil {
+		err = fmt.Errorf("[CreateBjjWalletFromEthKeyStore] Error generating BJJ Wallet. Account: %s - Error: %w", ethAccount.Address.Hex(), err)
+		return
+	}
+	bjjWallet.EthAccount = ethAccount
+	return
+}
+
+// KeyStoreEthSigner is an EthSigner backed by an account of a go-ethereum keystore
+type KeyStoreEthSigner struct {
+	ks         *keystore.KeyStore
+	ethAccount accounts.Account
+	passphrase string
+}
+
+// NewKeyStoreEthSigner creates an EthSigner that signs with the keystore account unlocked by the passphrase
+func NewKeyStoreEthSigner(ks *keystore.KeyStore, ethAccount accounts.Account, passphrase string) *KeyStoreEthSigner {
+	return &KeyStoreEthSigner{
+		ks:         ks,
+		ethAccount: ethAccount,
+		passphrase: passphrase,
+	}
+}
+
+// EthAddress returns the Ethereum address of the keystore account
+func (s *KeyStoreEthSigner) EthAddress() common.Address {
+	return s.ethAccount.Address
+}
+
+// SignHash signs the hash with the keystore account
+func (s *KeyStoreEthSigner) SignHash(hash []byte) ([]byte, error) {
+	return s.ks.SignHashWithPassphrase(s.ethAccount, s.passphrase, hash)
+}
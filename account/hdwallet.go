@@ -0,0 +1,142 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
+)
+
+// ErrDerivationIndexNotFound is returned when no derivation index up to the limit owns the hez address
+var ErrDerivationIndexNotFound = errors.New("derivation index not found for the hez address")
+
+// NewMnemonic generates a new BIP-39 mnemonic. bits is the entropy size and must be a multiple of 32 between 128
+// (12 words) and 256 (24 words)
+func NewMnemonic(bits int) (mnemonic string, err error) {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		err = fmt.Errorf("[NewMnemonic] Invalid entropy size: %d. It must be a multiple of 32 between 128 and 256", bits)
+		return
+	}
+	mnemonic, err = hdwallet.NewMnemonic(bits)
+	if err != nil {
+		err = fmt.Errorf("[NewMnemonic] Error generating mnemonic - Error: %s\n", err.Error())
+		return
+	}
+	return
+}
+
+// CreateBjjWalletFromMnemonicWithIndex creates a Babyjubjub Wallet from the Ethereum account derived at
+// m/44'/60'/0'/0/index
+func CreateBjjWalletFromMnemonicWithIndex(mnemonic string, index int) (bjjWallet BJJWallet, ethAccount accounts.Account, err error) {
+	if index < 0 {
+		err = fmt.Errorf("[CreateBjjWalletFromMnemonicWithIndex] Invalid derivation index: %d", index)
+		return
+	}
+	return CreateBjjWalletFromMnemonicWithPath(mnemonic, fmt.Sprintf(ethDerivationPath, index))
+}
+
+// CreateBjjWalletFromMnemonicWithPath creates a Babyjubjub Wallet from the Ethereum account derived at a custom path
+func CreateBjjWalletFromMnemonicWithPath(mnemonic string, derivationPath string) (bjjWallet BJJWallet, ethAccount accounts.Account, err error) {
+	return CreateBjjWalletWithAccCreationSignatureFromMnemonicWithPath(mnemonic, derivationPath, 0, "")
+}
+
+// CreateBjjWalletWithAccCreationSignatureFromMnemonicWithPath creates a Babyjubjub Wallet from the Ethereum account
+// derived at a custom path with hermez account creation signature
+func CreateBjjWalletWithAccCreationSignatureFromMnemonicWithPath(mnemonic string, derivationPath string, chainID int, rollupContractAddress string) (bjjWallet BJJWallet, ethAccount accounts.Account, err error) {
+	path, err := hdwallet.ParseDerivationPath(derivationPath)
+	if err != nil {
+		err = fmt.Errorf("[CreateBjjWalletFromMnemonicWithPath] Invalid derivation path: %s - Error: %s\n", derivationPath, err.Error())
+		return
+	}
+	ethWallet, err := hdwallet.NewFromMnemonic(mnemonic)
+	if err != nil {
+		err = fmt.Errorf("[CreateBjjWalletFromMnemonicWithPath] Error creating ethereum account from mnemonic - Error: %s\n", err.Error())
+		log.Println(err.Error())
+		return
+	}
+	return createBjjWalletFromHDWallet(ethWallet, path, chainID, rollupContractAddress)
+}
+
+// CreateBjjWalletsFromMnemonic creates count Babyjubjub Wallets derived from m/44'/60'/0'/0/fromIndex onwards
+func CreateBjjWalletsFromMnemonic(mnemonic string, fromIndex int, count int) (bjjWallets []BJJWallet, err error) {
+	return CreateBjjWalletsWithAccCreationSignatureFromMnemonic(mnemonic, fromIndex, count, 0, "")
+}
+
+// CreateBjjWalletsWithAccCreationSignatureFromMnemonic creates count Babyjubjub Wallets derived from
+// m/44'/60'/0'/0/fromIndex onwards with hermez account creation signature
+func CreateBjjWalletsWithAccCreationSignatureFromMnemonic(mnemonic string, fromIndex int, count int, chainID int, rollupContractAddress string) (bjjWallets []BJJWallet, err error) {
+	if fromIndex < 0 || count < 1 {
+		err = fmt.Errorf("[CreateBjjWalletsFromMnemonic] Invalid derivation range. From index: %d - Count: %d", fromIndex, count)
+		return
+	}
+	ethWallet, err := hdwallet.NewFromMnemonic(mnemonic)
+	if err != nil {
+		err = fmt.Errorf("[CreateBjjWalletsFromMnemonic] Error creating ethereum account from mnemonic - Error: %s\n", err.Error())
+		log.Println(err.Error())
+		return
+	}
+	bjjWallets = make([]BJJWallet, 0, count)
+	for index := fromIndex; index < fromIndex+count; index++ {
+		path := hdwallet.MustParseDerivationPath(fmt.Sprintf(ethDerivationPath, index))
+		var bjjWallet BJJWallet
+		bjjWallet, _, err = createBjjWalletFromHDWallet(ethWallet, path, chainID, rollupContractAddress)
+		if err != nil {
+			err = fmt.Errorf("[CreateBjjWalletsFromMnemonic] Error generating BJJ Wallet at index %d - Error: %s\n", index, err.Error())
+			return
+		}
+		bjjWallets = append(bjjWallets, bjjWallet)
+	}
+	return
+}
+
+// FindDerivationIndex looks for the derivation index, from 0 up to maxIndex, whose wallet owns the hez Ethereum
+// address (hez:0x...) or the hez BJJ address
+func FindDerivationIndex(mnemonic string, hezAddress string, maxIndex int) (index int, bjjWallet BJJWallet, err error) {
+	ethWallet, err := hdwallet.NewFromMnemonic(mnemonic)
+	if err != nil {
+		err = fmt.Errorf("[FindDerivationIndex] Error creating ethereum account from mnemonic - Error: %s\n", err.Error())
+		log.Println(err.Error())
+		return
+	}
+	if !strings.HasPrefix(hezAddress, "hez:") {
+		hezAddress = "hez:" + hezAddress
+	}
+	for index = 0; index <= maxIndex; index++ {
+		path := hdwallet.MustParseDerivationPath(fmt.Sprintf(ethDerivationPath, index))
+		bjjWallet, _, err = createBjjWalletFromHDWallet(ethWallet, path, 0, "")
+		if err != nil {
+			err = fmt.Errorf("[FindDerivationIndex] Error generating BJJ Wallet at index %d - Error: %s\n", index, err.Error())
+			return
+		}
+		if strings.EqualFold(bjjWallet.HezEthAddress, hezAddress) || bjjWallet.HezBjjAddress == hezAddress {
+			return
+		}
+	}
+	err = fmt.Errorf("[FindDerivationIndex] Address: %s - Max index: %d - Error: %w", hezAddress, maxIndex, ErrDerivationIndexNotFound)
+	return -1, BJJWallet{}, err
+}
+
+// createBjjWalletFromHDWallet derives the Ethereum account at path and generates its BJJ Wallet signing the hermez
+// standard message
+func createBjjWalletFromHDWallet(ethWallet *hdwallet.Wallet, path accounts.DerivationPath, chainID int, rollupContractAddress string) (bjjWallet BJJWallet, ethAccount accounts.Account, err error) {
+	ethAccount, err = ethWallet.Derive(path, false)
+	if err != nil {
+		err = fmt.Errorf("[createBjjWalletFromHDWallet] Error deriving the account at %s - Error: %s\n", path.String(), err.Error())
+		return
+	}
+	ecdsaPvtKey, err := ethWallet.PrivateKey(ethAccount)
+	if err != nil {
+		err = fmt.Errorf("[createBjjWalletFromHDWallet] Error getting private key of account %s - Error: %s\n", ethAccount.Address.Hex(), err.Error())
+		return
+	}
+	bjjWallet, _, err = CreateBjjWalletWithAccCreationSignatureFromPvtKey(ecdsaPvtKey, chainID, rollupContractAddress)
+	if err != nil {
+		err = fmt.Errorf("[createBjjWalletFromHDWallet] Error generating BJJ Wallet. Account: %s - Error: %s\n", ethAccount.Address.Hex(), err.Error())
+		return
+	}
+	bjjWallet.EthAccount = ethAccount
+	return
+}
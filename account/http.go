@@ -1,6 +1,7 @@
 package account
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,6 +11,11 @@ import (
 	"github.com/hermeznetwork/hermez-go-sdk/client"
 )
 
+const accountsPageLimit = 2049
+
+// ErrAccountNotFound is returned when the hermez node has no account with the requested index
+var ErrAccountNotFound = errors.New("account not found")
+
 // GetAccountInfo connects to a hermez node and pull account data
 func GetAccountInfo(hezClient client.HermezClient, account string) (hezAccount AccountAPIResponse, err error) {
 	log.Println("[Account][GetAccountInfo] Pulling account info ", account, " from a coordinator...")
@@ -45,6 +51,7 @@ func GetAccountInfo(hezClient client.HermezClient, account string) (hezAccount A
 }
 
 func formatHezAccountAddress(account string) (hezAccountString string) {
+	account = strings.TrimPrefix(account, "hez:")
 	if strings.HasPrefix(account, "0x") {
 		hezAccountString = "hezEthereumAddress=hez:" + account
 		return
@@ -60,3 +67,75 @@ func formatHezAccountAddress(account string) (hezAccountString string) {
 	}
 	return
 }
+
+// GetAllAccountsInfo connects to a hermez node and pull every account of a hez Ethereum address or BJJ address,
+// going through all the result pages
+func GetAllAccountsInfo(hezClient client.HermezClient, account string) (accounts []Account, err error) {
+	if len(account) < 5 {
+		err = fmt.Errorf("[Account][GetAllAccountsInfo] Invalid account to query: %s", account)
+		return
+	}
+	if len(hezClient.BootCoordinatorURL) < 10 {
+		err = fmt.Errorf("[Account][GetAllAccountsInfo] Boot Coordinator is not set : %s", hezClient.BootCoordinatorURL)
+		return
+	}
+	filter := formatHezAccountAddress(account)
+	fromItem := 0
+	for {
+		url := fmt.Sprintf("/v1/accounts?%s&order=ASC&limit=%d&fromItem=%d", filter, accountsPageLimit, fromItem)
+		var req *http.Request
+		req, err = hezClient.BootCoordinatorClient.New().Get(url).Request()
+		if err != nil {
+			err = fmt.Errorf("[Account][GetAllAccountsInfo] Error creating pulling account info request: %s", err.Error())
+			return
+		}
+		var page AccountAPIResponse
+		var failureBody interface{}
+		var res *http.Response
+		res, err = hezClient.BootCoordinatorClient.Do(req, &page, &failureBody)
+		if err != nil {
+			err = fmt.Errorf("[Account][GetAllAccountsInfo] Error pulling account info from hermez node: %s - Error: %s", hezClient.BootCoordinatorURL, err.Error())
+			return
+		}
+		if res.StatusCode != http.StatusOK {
+			err = fmt.Errorf("[Account][GetAllAccountsInfo] Error pulling account info from hermez node: %+v - Error: %d", failureBody, res.StatusCode)
+			return
+		}
+		accounts = append(accounts, page.Accounts...)
+		if page.PendingItems < 1 || len(page.Accounts) < 1 {
+			return
+		}
+		fromItem = page.Accounts[len(page.Accounts)-1].ItemID + 1
+	}
+}
+
+// GetAccountByIdx connects to a hermez node and pull the account with the hez account index (hez:TKN:256)
+func GetAccountByIdx(hezClient client.HermezClient, accountIdx string) (account Account, err error) {
+	if len(hezClient.BootCoordinatorURL) < 10 {
+		err = fmt.Errorf("[Account][GetAccountByIdx] Boot Coordinator is not set : %s", hezClient.BootCoordinatorURL)
+		return
+	}
+	if !strings.HasPrefix(accountIdx, "hez:") {
+		accountIdx = "hez:" + accountIdx
+	}
+	req, err := hezClient.BootCoordinatorClient.New().Get("/v1/accounts/" + accountIdx).Request()
+	if err != nil {
+		err = fmt.Errorf("[Account][GetAccountByIdx] Error creating pulling account info request: %s", err.Error())
+		return
+	}
+	var failureBody interface{}
+	res, err := hezClient.BootCoordinatorClient.Do(req, &account, &failureBody)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		err = fmt.Errorf("[Account][GetAccountByIdx] Account: %s - Error: %w", accountIdx, ErrAccountNotFound)
+		return
+	}
+	if res != nil && res.StatusCode != http.StatusOK {
+		err = fmt.Errorf("[Account][GetAccountByIdx] Error pulling account info from hermez node: %+v - Error: %d", failureBody, res.StatusCode)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("[Account][GetAccountByIdx] Error pulling account info from hermez node: %s - Error: %s", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	return
+}
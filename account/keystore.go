@@ -0,0 +1,315 @@
+package account
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+const (
+	// BJJKeyStoreVersion is the version of the encrypted BJJWallet JSON format
+	BJJKeyStoreVersion = 1
+	// StandardScryptN is the scrypt N parameter recommended to encrypt a BJJWallet (same as go-ethereum)
+	StandardScryptN = keystore.StandardScryptN
+	// StandardScryptP is the scrypt P parameter recommended to encrypt a BJJWallet (same as go-ethereum)
+	StandardScryptP = keystore.StandardScryptP
+	// LightScryptN is a cheaper scrypt N parameter, for tests and constrained devices
+	LightScryptN = keystore.LightScryptN
+	// LightScryptP is a cheaper scrypt P parameter, for tests and constrained devices
+	LightScryptP = keystore.LightScryptP
+
+	keyStoreFilePrefix = "hez--"
+	keyStoreFileSuffix = ".json"
+)
+
+var (
+	// ErrKeyStoreWalletNotFound is returned when the keystore directory has no wallet for the address
+	ErrKeyStoreWalletNotFound = errors.New("wallet not found in the keystore")
+	// ErrKeyStoreWalletExists is returned when the keystore directory already has a wallet for the address
+	ErrKeyStoreWalletExists = errors.New("wallet already exists in the keystore")
+	// ErrKeyStoreInvalidPassword is returned when the password can't decrypt the wallet
+	ErrKeyStoreInvalidPassword = keystore.ErrDecrypt
+	// ErrKeyStoreCorrupted is returned when the decrypted keys don't match the addresses stored with them
+	ErrKeyStoreCorrupted = errors.New("decrypted keys don't match the wallet addresses")
+)
+
+// EncryptedBJJWallet is the JSON representation of a BJJWallet encrypted with scrypt and AES-128-CTR, using the
+// same crypto section as the go-ethereum V3 keystore
+type EncryptedBJJWallet struct {
+	Version                      int                 `json:"version"`
+	Address                      string              `json:"address"`
+	HezEthAddress                string              `json:"hezEthereumAddress"`
+	HezBjjAddress                string              `json:"hezBjjAddress"`
+	AccountCreationAuthSignature string              `json:"accountCreationAuthSignature,omitempty"`
+	Crypto                       keystore.CryptoJSON `json:"crypto"`
+}
+
+// encryptedBJJWalletKeys is the plain content of EncryptedBJJWallet.Crypto
+type encryptedBJJWalletKeys struct {
+	EthPrivateKey string `json:"ethPrivateKey,omitempty"`
+	BjjPrivateKey string `json:"bjjPrivateKey"`
+}
+
+// EncryptBJJWallet encrypts the Ethereum private key and the BJJ private key of the wallet with the password
+func EncryptBJJWallet(bjjWallet BJJWallet, password string, scryptN, scryptP int) (keyJSON []byte, err error) {
+	if !bjjWallet.hasBJJPrivateKey() {
+		err = fmt.Errorf("[EncryptBJJWallet] Account: %s - Error: %w", bjjWallet.HezEthAddress, ErrBJJPrivateKeyNotAvailable)
+		return
+	}
+	keys := encryptedBJJWalletKeys{
+		BjjPrivateKey: hex.EncodeToString(bjjWallet.privateKey[:]),
+	}
+	if bjjWallet.hasEthPrivateKey() {
+		keys.EthPrivateKey = hex.EncodeToString(crypto.FromECDSA(bjjWallet.ethPrivateKey))
+	}
+	plainKeys, err := json.Marshal(keys)
+	if err != nil {
+		err = fmt.Errorf("[EncryptBJJWallet] Error marshaling keys. Account: %s - Error: %s", bjjWallet.HezEthAddress, err.Error())
+		return
+	}
+	cryptoJSON, err := keystore.EncryptDataV3(plainKeys, []byte(password), scryptN, scryptP)
+	if err != nil {
+		err = fmt.Errorf("[EncryptBJJWallet] Error encrypting keys. Account: %s - Error: %s", bjjWallet.HezEthAddress, err.Error())
+		return
+	}
+	encrypted := EncryptedBJJWallet{
+		Version:                      BJJKeyStoreVersion,
+		Address:                      bjjWallet.EthAccount.Address.Hex(),
+		HezEthAddress:                bjjWallet.HezEthAddress,
+		HezBjjAddress:                bjjWallet.HezBjjAddress,
+		AccountCreationAuthSignature: bjjWallet.AccountCreationAuthSignature,
+		Crypto:                       cryptoJSON,
+	}
+	return json.MarshalIndent(encrypted, "", "  ")
+}
+
+// DecryptBJJWallet decrypts a wallet encrypted by EncryptBJJWallet and checks that the keys match its addresses
+func DecryptBJJWallet(keyJSON []byte, password string) (bjjWallet BJJWallet, err error) {
+	var encrypted EncryptedBJJWallet
+	if err = json.Unmarshal(keyJSON, &encrypted); err != nil {
+		err = fmt.Errorf("[DecryptBJJWallet] Error unmarshaling encrypted wallet - Error: %s", err.Error())
+		return
+	}
+	if encrypted.Version != BJJKeyStoreVersion {
+		err = fmt.Errorf("[DecryptBJJWallet] Unsupported encrypted wallet version: %d", encrypted.Version)
+		return
+	}
+	plainKeys, err := keystore.DecryptDataV3(encrypted.Crypto, password)
+	if err != nil {
+		err = fmt.Errorf("[DecryptBJJWallet] Account: %s - Error: %w", encrypted.Address, err)
+		return
+	}
+	var keys encryptedBJJWalletKeys
+	if err = json.Unmarshal(plainKeys, &keys); err != nil {
+		err = fmt.Errorf("[DecryptBJJWallet] Error unmarshaling decrypted keys. Account: %s - Error: %s", encrypted.Address, err.Error())
+		return
+	}
+
+	ethAccount := accounts.Account{Address: common.HexToAddress(encrypted.Address)}
+	if len(keys.EthPrivateKey) > 0 {
+		ecdsaPvtKey, errKey := crypto.HexToECDSA(keys.EthPrivateKey)
+		if errKey != nil {
+			err = fmt.Errorf("[DecryptBJJWallet] Account: %s - Error: %w", encrypted.Address, ErrKeyStoreCorrupted)
+			return
+		}
+		bjjWallet, _, err = CreateBjjWalletWithAccCreationSignatureFromPvtKey(ecdsaPvtKey, 0, "")
+		if err != nil {
+			err = fmt.Errorf("[DecryptBJJWallet] Error generating BJJ Wallet. Account: %s - Error: %s", encrypted.Address, err.Error())
+			return
+		}
+		if bjjWallet.EthAccount.Address != ethAccount.Address || hex.EncodeToString(bjjWallet.privateKey[:]) != keys.BjjPrivateKey {
+			err = fmt.Errorf("[DecryptBJJWallet] Account: %s - Error: %w", encrypted.Address, ErrKeyStoreCorrupted)
+			return
+		}
+	} else {
+		var bjjPvtKey babyjub.PrivateKey
+		decodedBjjPvtKey, errKey := hex.DecodeString(keys.BjjPrivateKey)
+		if errKey != nil || len(decodedBjjPvtKey) != len(bjjPvtKey) {
+			err = fmt.Errorf("[DecryptBJJWallet] Account: %s - Error: %w", encrypted.Address, ErrKeyStoreCorrupted)
+			return
+		}
+		copy(bjjPvtKey[:], decodedBjjPvtKey)
+		bjjWallet, err = newBJJWallet(bjjPvtKey, ethAccount)
+		if err != nil {
+			err = fmt.Errorf("[DecryptBJJWallet] Error generating BJJ Wallet. Account: %s - Error: %s", encrypted.Address, err.Error())
+			return
+		}
+	}
+	if bjjWallet.HezBjjAddress != encrypted.HezBjjAddress {
+		err = fmt.Errorf("[DecryptBJJWallet] Account: %s - Error: %w", encrypted.Address, ErrKeyStoreCorrupted)
+		return BJJWallet{}, err
+	}
+	bjjWallet.AccountCreationAuthSignature = encrypted.AccountCreationAuthSignature
+	return
+}
+
+// ChangeBJJWalletPassword decrypts the wallet with the old password and encrypts it again with the new one
+func ChangeBJJWalletPassword(keyJSON []byte, oldPassword, newPassword string, scryptN, scryptP int) (newKeyJSON []byte, err error) {
+	bjjWallet, err := DecryptBJJWallet(keyJSON, oldPassword)
+	if err != nil {
+		return
+	}
+	return EncryptBJJWallet(bjjWallet, newPassword, scryptN, scryptP)
+}
+
+// BJJKeyStore keeps encrypted BJJWallets in a directory, one file per Ethereum address
+type BJJKeyStore struct {
+	dir     string
+	scryptN int
+	scryptP int
+}
+
+// BJJKeyStoreEntry describes a wallet stored in a BJJKeyStore without decrypting it
+type BJJKeyStoreEntry struct {
+	Address       common.Address
+	HezEthAddress string
+	HezBjjAddress string
+	Path          string
+}
+
+// NewBJJKeyStore creates a keystore in the directory, creating it if needed
+func NewBJJKeyStore(dir string, scryptN, scryptP int) (ks *BJJKeyStore, err error) {
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		err = fmt.Errorf("[NewBJJKeyStore] Error creating keystore directory: %s - Error: %s", dir, err.Error())
+		return
+	}
+	ks = &BJJKeyStore{
+		dir:     dir,
+		scryptN: scryptN,
+		scryptP: scryptP,
+	}
+	return
+}
+
+// Store encrypts the wallet and saves it in the keystore directory
+func (ks *BJJKeyStore) Store(bjjWallet BJJWallet, password string) (path string, err error) {
+	path = ks.walletPath(bjjWallet.EthAccount.Address)
+	if _, err = os.Stat(path); err == nil {
+		err = fmt.Errorf("[BJJKeyStore][Store] Account: %s - Error: %w", bjjWallet.EthAccount.Address.Hex(), ErrKeyStoreWalletExists)
+		return
+	}
+	keyJSON, err := EncryptBJJWallet(bjjWallet, password, ks.scryptN, ks.scryptP)
+	if err != nil {
+		return
+	}
+	err = writeKeyStoreFile(path, keyJSON)
+	return
+}
+
+// Load decrypts the wallet of the Ethereum address
+func (ks *BJJKeyStore) Load(ethAddress string, password string) (bjjWallet BJJWallet, err error) {
+	keyJSON, err := ks.readWallet(ethAddress)
+	if err != nil {
+		return
+	}
+	return DecryptBJJWallet(keyJSON, password)
+}
+
+// ChangePassword encrypts again the wallet of the Ethereum address with a new password
+func (ks *BJJKeyStore) ChangePassword(ethAddress string, oldPassword, newPassword string) (err error) {
+	keyJSON, err := ks.readWallet(ethAddress)
+	if err != nil {
+		return
+	}
+	newKeyJSON, err := ChangeBJJWalletPassword(keyJSON, oldPassword, newPassword, ks.scryptN, ks.scryptP)
+	if err != nil {
+		return
+	}
+	return writeKeyStoreFile(ks.walletPath(common.HexToAddress(strings.TrimPrefix(ethAddress, "hez:"))), newKeyJSON)
+}
+
+// Delete removes the wallet of the Ethereum address. The password is required to avoid deleting it by mistake
+func (ks *BJJKeyStore) Delete(ethAddress string, password string) (err error) {
+	if _, err = ks.Load(ethAddress, password); err != nil {
+		return
+	}
+	return os.Remove(ks.walletPath(common.HexToAddress(strings.TrimPrefix(ethAddress, "hez:"))))
+}
+
+// List returns the wallets in the keystore directory sorted by address
+func (ks *BJJKeyStore) List() (entries []BJJKeyStoreEntry, err error) {
+	files, err := ioutil.ReadDir(ks.dir)
+	if err != nil {
+		err = fmt.Errorf("[BJJKeyStore][List] Error reading keystore directory: %s - Error: %s", ks.dir, err.Error())
+		return
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasPrefix(file.Name(), keyStoreFilePrefix) || !strings.HasSuffix(file.Name(), keyStoreFileSuffix) {
+			continue
+		}
+		path := filepath.Join(ks.dir, file.Name())
+		keyJSON, errRead := ioutil.ReadFile(path)
+		if errRead != nil {
+			err = fmt.Errorf("[BJJKeyStore][List] Error reading wallet file: %s - Error: %s", path, errRead.Error())
+			return
+		}
+		var encrypted EncryptedBJJWallet
+		if errJSON := json.Unmarshal(keyJSON, &encrypted); errJSON != nil || !common.IsHexAddress(encrypted.Address) {
+			continue
+		}
+		entries = append(entries, BJJKeyStoreEntry{
+			Address:       common.HexToAddress(encrypted.Address),
+			HezEthAddress: encrypted.HezEthAddress,
+			HezBjjAddress: encrypted.HezBjjAddress,
+			Path:          path,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].Address.Hex()) < strings.ToLower(entries[j].Address.Hex())
+	})
+	return
+}
+
+// readWallet reads the encrypted wallet of the Ethereum address
+func (ks *BJJKeyStore) readWallet(ethAddress string) (keyJSON []byte, err error) {
+	ethAddress = strings.TrimPrefix(ethAddress, "hez:")
+	if !common.IsHexAddress(ethAddress) {
+		err = fmt.Errorf("[BJJKeyStore] Invalid Ethereum address: %s", ethAddress)
+		return
+	}
+	keyJSON, err = ioutil.ReadFile(ks.walletPath(common.HexToAddress(ethAddress)))
+	if os.IsNotExist(err) {
+		err = fmt.Errorf("[BJJKeyStore] Account: %s - Error: %w", ethAddress, ErrKeyStoreWalletNotFound)
+		return
+	}
+	return
+}
+
+// walletPath is the file where the wallet of the Ethereum address is stored
+func (ks *BJJKeyStore) walletPath(address common.Address) string {
+	return filepath.Join(ks.dir, keyStoreFilePrefix+strings.ToLower(address.Hex()[2:])+keyStoreFileSuffix)
+}
+
+// writeKeyStoreFile writes the file atomically, readable only by the owner
+func writeKeyStoreFile(path string, content []byte) (err error) {
+	tmpFile, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		err = fmt.Errorf("[BJJKeyStore] Error creating temporary file - Error: %s", err.Error())
+		return
+	}
+	if _, err = tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		err = fmt.Errorf("[BJJKeyStore] Error writing temporary file - Error: %s", err.Error())
+		return
+	}
+	tmpFile.Close()
+	if err = os.Rename(tmpFile.Name(), path); err != nil {
+		os.Remove(tmpFile.Name())
+		err = fmt.Errorf("[BJJKeyStore] Error writing wallet file: %s - Error: %s", path, err.Error())
+		return
+	}
+	return
+}
@@ -1,6 +1,7 @@
 package account
 
 import (
+	"crypto/ecdsa"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -34,14 +35,16 @@ type Token struct {
 	Symbol           string    `json:"symbol"`
 }
 
-// BJJWallet BJJ Wallet
+// BJJWallet BJJ Wallet. The private keys are shared by the copies of the wallet, they are never printed nor
+// marshaled and can be read only with ExportBJJPrivateKey and ExportEthPrivateKey
 type BJJWallet struct {
-	PrivateKey      babyjub.PrivateKey
-	PublicKey       babyjub.PublicKeyComp
-	HezBjjAddress   string
-	EthAccount      accounts.Account
-	HezEthAddress   string
+	privateKey                   *babyjub.PrivateKey
+	PublicKey                    babyjub.PublicKeyComp
+	HezBjjAddress                string
+	EthAccount                   accounts.Account
+	HezEthAddress                string
 	AccountCreationAuthSignature string
+	ethPrivateKey                *ecdsa.PrivateKey
 }
 
 // AccountCreation is used to submit new account creation to a Hermez Node
@@ -50,3 +53,11 @@ type AccountCreation struct {
 	HezBjjAddress   string `json:"bjj"`
 	Signature       string `json:"signature"`
 }
+
+// AccountCreationAuth is the account creation authorization stored in a Hermez Node
+type AccountCreationAuth struct {
+	EthereumAddress string    `json:"hezEthereumAddress"`
+	HezBjjAddress   string    `json:"bjj"`
+	Signature       string    `json:"signature"`
+	Timestamp       time.Time `json:"timestamp"`
+}
@@ -0,0 +1,234 @@
+package account
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/hermeznetwork/hermez-node/common/apitypes"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+const (
+	ownershipChallengeHeader = "Hermez Network address ownership proof"
+	// ownershipProofTag separates the Poseidon hash of the challenge from the hashes of Hermez transactions
+	ownershipProofTag    = "HERMEZ_OWNERSHIP_PROOF_V1"
+	ownershipNonceLength = 16
+)
+
+var (
+	// ErrOwnershipChallengeExpired is returned when the proof is verified after the challenge expiration time
+	ErrOwnershipChallengeExpired = errors.New("ownership challenge expired")
+	// ErrOwnershipChallengeMismatch is returned when the proof was made for another challenge
+	ErrOwnershipChallengeMismatch = errors.New("ownership proof doesn't answer the issued challenge")
+	// ErrOwnershipProofMissingSignature is returned when the proof has no signature for the kind of address challenged
+	ErrOwnershipProofMissingSignature = errors.New("ownership proof has no signature for the challenged address")
+	// ErrOwnershipProofInvalidSignature is returned when the signature wasn't produced by the key of the address
+	ErrOwnershipProofInvalidSignature = errors.New("invalid ownership proof signature")
+)
+
+// OwnershipChallenge is the message a user signs to prove they control a hez: Ethereum or BJJ address. Domain
+// identifies the verifier, so a proof given to one service can't be replayed to another one
+type OwnershipChallenge struct {
+	Domain     string    `json:"domain"`
+	HezAddress string    `json:"hezAddress"`
+	Nonce      string    `json:"nonce"`
+	IssuedAt   time.Time `json:"issuedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// OwnershipProof is the signed OwnershipChallenge. BJJSignature is the hex compressed Babyjubjub signature of
+// OwnershipChallenge.PoseidonHash, EthSignature the personal_sign (EIP-191) signature of OwnershipChallenge.Message
+type OwnershipProof struct {
+	Challenge    OwnershipChallenge `json:"challenge"`
+	BJJSignature string             `json:"bjjSignature,omitempty"`
+	EthSignature string             `json:"ethSignature,omitempty"`
+}
+
+// NewOwnershipChallenge builds a challenge for hezAddress (hez:0x... or hez BJJ address) with a random nonce,
+// valid for ttl
+func NewOwnershipChallenge(domain string, hezAddress string, ttl time.Duration) (challenge OwnershipChallenge, err error) {
+	if len(domain) < 1 || strings.ContainsAny(domain, "\r\n") {
+		err = fmt.Errorf("[Account][NewOwnershipChallenge] Invalid domain: %q", domain)
+		return
+	}
+	if ttl <= 0 {
+		err = fmt.Errorf("[Account][NewOwnershipChallenge] Invalid ttl: %s", ttl)
+		return
+	}
+	if _, _, err = parseHezAddress(hezAddress); err != nil {
+		err = fmt.Errorf("[Account][NewOwnershipChallenge] Invalid hez address: %s - Error: %s", hezAddress, err.Error())
+		return
+	}
+	nonce := make([]byte, ownershipNonceLength)
+	if _, err = rand.Read(nonce); err != nil {
+		err = fmt.Errorf("[Account][NewOwnershipChallenge] Error generating nonce - Error: %s", err.Error())
+		return
+	}
+	issuedAt := time.Now().UTC().Truncate(time.Second)
+	challenge = OwnershipChallenge{
+		Domain:     domain,
+		HezAddress: hezAddress,
+		Nonce:      hex.EncodeToString(nonce),
+		IssuedAt:   issuedAt,
+		ExpiresAt:  issuedAt.Add(ttl),
+	}
+	return
+}
+
+// Message returns the text signed by the Ethereum key, and shown to the user by external wallets
+func (c OwnershipChallenge) Message() string {
+	return fmt.Sprintf("%s\nDomain: %s\nAddress: %s\nNonce: %s\nIssued At: %s\nExpiration Time: %s",
+		ownershipChallengeHeader, c.Domain, c.HezAddress, c.Nonce,
+		c.IssuedAt.UTC().Format(time.RFC3339), c.ExpiresAt.UTC().Format(time.RFC3339))
+}
+
+// PoseidonHash returns the hash signed by the Babyjubjub key: Poseidon(tag, keccak256(Message)) with the keccak
+// hash split in two 128 bits halves to fit the field
+func (c OwnershipChallenge) PoseidonHash() (*big.Int, error) {
+	msgHash := crypto.Keccak256([]byte(c.Message()))
+	return poseidon.Hash([]*big.Int{
+		new(big.Int).SetBytes([]byte(ownershipProofTag)),
+		new(big.Int).SetBytes(msgHash[:16]),
+		new(big.Int).SetBytes(msgHash[16:]),
+	})
+}
+
+// SignOwnershipChallenge signs the challenge with the Babyjubjub key, the Ethereum key or both. Any of the signers
+// can be nil, and the wallet is both signers: SignOwnershipChallenge(challenge, bjjWallet, bjjWallet)
+func SignOwnershipChallenge(challenge OwnershipChallenge, bjjSigner BJJSigner, ethSigner EthSigner) (proof OwnershipProof, err error) {
+	if bjjSigner == nil && ethSigner == nil {
+		err = fmt.Errorf("[Account][SignOwnershipChallenge] Address: %s - Error: %w", challenge.HezAddress, ErrOwnershipProofMissingSignature)
+		return
+	}
+	proof.Challenge = challenge
+	if bjjSigner != nil {
+		hash, errHash := challenge.PoseidonHash()
+		if errHash != nil {
+			err = fmt.Errorf("[Account][SignOwnershipChallenge] Error hashing challenge - Error: %s", errHash.Error())
+			return OwnershipProof{}, err
+		}
+		signature, errSign := bjjSigner.SignPoseidon(hash)
+		if errSign != nil {
+			err = fmt.Errorf("[Account][SignOwnershipChallenge] Error signing with BJJ key - Error: %w", errSign)
+			return OwnershipProof{}, err
+		}
+		signatureComp := signature.Compress()
+		proof.BJJSignature = hex.EncodeToString(signatureComp[:])
+	}
+	if ethSigner != nil {
+		signature, errSign := ethSigner.SignHash(accounts.TextHash([]byte(challenge.Message())))
+		if errSign != nil {
+			err = fmt.Errorf("[Account][SignOwnershipChallenge] Error signing with Ethereum key - Error: %w", errSign)
+			return OwnershipProof{}, err
+		}
+		signature[crypto.RecoveryIDOffset] += 27
+		proof.EthSignature = "0x" + hex.EncodeToString(signature)
+	}
+	return
+}
+
+// VerifyOwnershipProof checks that proof answers the issued challenge before its expiration, and that it was signed
+// by the key of the challenged address: the Ethereum key for hez:0x... addresses, the Babyjubjub key for hez BJJ
+// addresses. The signature of the other key, if any, is not checked.
+func VerifyOwnershipProof(challenge OwnershipChallenge, proof OwnershipProof) (err error) {
+	if !sameOwnershipChallenge(challenge, proof.Challenge) {
+		err = fmt.Errorf("[Account][VerifyOwnershipProof] Address: %s - Error: %w", challenge.HezAddress, ErrOwnershipChallengeMismatch)
+		return
+	}
+	if time.Now().After(challenge.ExpiresAt) {
+		err = fmt.Errorf("[Account][VerifyOwnershipProof] Address: %s - Expired at: %s - Error: %w", challenge.HezAddress, challenge.ExpiresAt, ErrOwnershipChallengeExpired)
+		return
+	}
+	ethAddress, bjjPubKeyComp, err := parseHezAddress(challenge.HezAddress)
+	if err != nil {
+		err = fmt.Errorf("[Account][VerifyOwnershipProof] Invalid hez address: %s - Error: %s", challenge.HezAddress, err.Error())
+		return
+	}
+	if bjjPubKeyComp != nil {
+		err = verifyOwnershipBJJSignature(challenge, proof.BJJSignature, *bjjPubKeyComp)
+	} else {
+		err = verifyOwnershipEthSignature(challenge, proof.EthSignature, ethAddress)
+	}
+	if err != nil {
+		err = fmt.Errorf("[Account][VerifyOwnershipProof] Address: %s - Error: %w", challenge.HezAddress, err)
+		return
+	}
+	return nil
+}
+
+func verifyOwnershipBJJSignature(challenge OwnershipChallenge, hexSignature string, bjjPubKeyComp babyjub.PublicKeyComp) error {
+	if len(hexSignature) < 1 {
+		return ErrOwnershipProofMissingSignature
+	}
+	var signatureComp babyjub.SignatureComp
+	decoded, err := hex.DecodeString(strings.TrimPrefix(hexSignature, "0x"))
+	if err != nil || len(decoded) != len(signatureComp) {
+		return ErrOwnershipProofInvalidSignature
+	}
+	copy(signatureComp[:], decoded)
+	signature, err := signatureComp.Decompress()
+	if err != nil {
+		return ErrOwnershipProofInvalidSignature
+	}
+	publicKey, err := bjjPubKeyComp.Decompress()
+	if err != nil {
+		return ErrOwnershipProofInvalidSignature
+	}
+	hash, err := challenge.PoseidonHash()
+	if err != nil || !publicKey.VerifyPoseidon(hash, signature) {
+		return ErrOwnershipProofInvalidSignature
+	}
+	return nil
+}
+
+func verifyOwnershipEthSignature(challenge OwnershipChallenge, hexSignature string, ethAddress common.Address) error {
+	if len(hexSignature) < 1 {
+		return ErrOwnershipProofMissingSignature
+	}
+	decoded, err := hex.DecodeString(strings.TrimPrefix(hexSignature, "0x"))
+	if err != nil {
+		return ErrOwnershipProofInvalidSignature
+	}
+	signature := normalizeEthSignature(decoded, 0)
+	if signature == nil {
+		return ErrOwnershipProofInvalidSignature
+	}
+	publicKey, err := crypto.SigToPub(accounts.TextHash([]byte(challenge.Message())), signature)
+	if err != nil || crypto.PubkeyToAddress(*publicKey) != ethAddress {
+		return ErrOwnershipProofInvalidSignature
+	}
+	return nil
+}
+
+// sameOwnershipChallenge compares the challenges field by field, as time.Time can't be compared with ==
+func sameOwnershipChallenge(a, b OwnershipChallenge) bool {
+	return a.Domain == b.Domain &&
+		a.HezAddress == b.HezAddress &&
+		a.Nonce == b.Nonce &&
+		a.IssuedAt.Equal(b.IssuedAt) &&
+		a.ExpiresAt.Equal(b.ExpiresAt)
+}
+
+// parseHezAddress decodes a hez:0x... Ethereum address or a hez BJJ address. bjjPubKeyComp is nil for Ethereum
+// addresses
+func parseHezAddress(hezAddress string) (ethAddress common.Address, bjjPubKeyComp *babyjub.PublicKeyComp, err error) {
+	if strings.HasPrefix(hezAddress, "hez:0x") {
+		ethAddress, err = apitypes.HezEthAddr(hezAddress).ToEthAddr()
+		return
+	}
+	bjj, err := apitypes.HezBJJ(hezAddress).ToBJJ()
+	if err != nil {
+		return
+	}
+	bjjPubKeyComp = &bjj
+	return
+}
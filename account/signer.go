@@ -0,0 +1,113 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	hezcommon "github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/common/apitypes"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+var (
+	// ErrEthPrivateKeyNotAvailable is returned when a BJJWallet is asked to sign with an Ethereum key it doesn't hold
+	ErrEthPrivateKeyNotAvailable = errors.New("ethereum private key is not available in this wallet")
+	// ErrBJJPrivateKeyNotAvailable is returned when the signer has no Babyjubjub private key: a destroyed BJJWallet
+	// or a WatchOnlyBJJSigner
+	ErrBJJPrivateKeyNotAvailable = errors.New("babyjubjub private key is not available in this wallet")
+)
+
+// BJJSigner signs Hermez L2 transactions and messages with a Babyjubjub key
+type BJJSigner interface {
+	// BJJPublicKey returns the compressed Babyjubjub public key, as babyjub.PublicKey.Compress does
+	BJJPublicKey() babyjub.PublicKeyComp
+	// SignPoseidon signs the Poseidon hash, as babyjub.PrivateKey.SignPoseidon does
+	SignPoseidon(msg *big.Int) (*babyjub.Signature, error)
+}
+
+// EthSigner signs hashes with an Ethereum key
+type EthSigner interface {
+	// EthAddress returns the Ethereum address of the key
+	EthAddress() common.Address
+	// SignHash returns a [R || S || V] signature of the hash with V as 0 or 1, as crypto.Sign does
+	SignHash(hash []byte) ([]byte, error)
+}
+
+// BJJPublicKey returns the compressed Babyjubjub public key of the wallet
+func (w BJJWallet) BJJPublicKey() babyjub.PublicKeyComp {
+	var bjjPubKeyComp babyjub.PublicKeyComp
+	copy(bjjPubKeyComp[:], hezcommon.SwapEndianness(w.PublicKey[:]))
+	return bjjPubKeyComp
+}
+
+// SignPoseidon signs msg with the wallet Babyjubjub private key. It fails with ErrBJJPrivateKeyNotAvailable when the
+// wallet was destroyed
+func (w BJJWallet) SignPoseidon(msg *big.Int) (*babyjub.Signature, error) {
+	if !w.hasBJJPrivateKey() {
+		return nil, fmt.Errorf("[BJJWallet][SignPoseidon] Account: %s - Error: %w", w.HezBjjAddress, ErrBJJPrivateKeyNotAvailable)
+	}
+	return w.privateKey.SignPoseidon(msg), nil
+}
+
+// EthAddress returns the Ethereum address of the wallet
+func (w BJJWallet) EthAddress() common.Address {
+	return w.EthAccount.Address
+}
+
+// SignHash signs hash with the wallet Ethereum private key. It fails with ErrEthPrivateKeyNotAvailable when the
+// wallet was not created from the Ethereum private key
+func (w BJJWallet) SignHash(hash []byte) ([]byte, error) {
+	if !w.hasEthPrivateKey() {
+		return nil, fmt.Errorf("[BJJWallet][SignHash] Account: %s - Error: %w", w.HezEthAddress, ErrEthPrivateKeyNotAvailable)
+	}
+	return crypto.Sign(hash, w.ethPrivateKey)
+}
+
+// CreateBjjWalletFromEthSigner Create a Babyjubjub Wallet signing the hermez standard message with the EthSigner, with
+// hermez account creation signature when chainID and rollupContractAddress are set
+func CreateBjjWalletFromEthSigner(ethSigner EthSigner, chainID int, rollupContractAddress string) (bjjWallet BJJWallet, err error) {
+	ethAccount := accounts.Account{Address: ethSigner.EthAddress()}
+	bjjWallet, err = createBjjWalletWithSignHash(ethAccount, ethSigner.SignHash, chainID, rollupContractAddress)
+	if err != nil {
+		err = fmt.Errorf("[CreateBjjWalletFromEthSigner] Error generating BJJ Wallet. Account: %s - Error: %w", ethAccount.Address.Hex(), err)
+		return
+	}
+	return
+}
+
+// CreateHermezAuthSignatureWithSigner creates the hermez wallet authentication signature with the EthSigner
+func CreateHermezAuthSignatureWithSigner(ethSigner EthSigner, bjjPubKeyComp babyjub.PublicKeyComp, chainID int, rollupContract common.Address) (string, error) {
+	return createHermezAuthSignatureWithSignHash(ethSigner.SignHash, accounts.Account{Address: ethSigner.EthAddress()}, bjjPubKeyComp, chainID, rollupContract)
+}
+
+// WatchOnlyBJJSigner is a BJJSigner that only knows the public key. It lets a machine without keys prepare
+// transactions that will be signed elsewhere, and fails to sign with ErrBJJPrivateKeyNotAvailable
+type WatchOnlyBJJSigner struct {
+	publicKey babyjub.PublicKeyComp
+}
+
+var _ BJJSigner = (*WatchOnlyBJJSigner)(nil)
+
+// NewWatchOnlyBJJSigner creates a WatchOnlyBJJSigner from a hez BJJ address
+func NewWatchOnlyBJJSigner(hezBjjAddress string) (signer *WatchOnlyBJJSigner, err error) {
+	bjjPubKeyComp, err := apitypes.HezBJJ(hezBjjAddress).ToBJJ()
+	if err != nil {
+		err = fmt.Errorf("[NewWatchOnlyBJJSigner] Invalid BJJ address: %s - Error: %s", hezBjjAddress, err.Error())
+		return
+	}
+	return &WatchOnlyBJJSigner{publicKey: bjjPubKeyComp}, nil
+}
+
+// BJJPublicKey returns the compressed Babyjubjub public key
+func (s *WatchOnlyBJJSigner) BJJPublicKey() babyjub.PublicKeyComp {
+	return s.publicKey
+}
+
+// SignPoseidon always fails with ErrBJJPrivateKeyNotAvailable
+func (s *WatchOnlyBJJSigner) SignPoseidon(msg *big.Int) (*babyjub.Signature, error) {
+	return nil, fmt.Errorf("[WatchOnlyBJJSigner][SignPoseidon] Error: %w", ErrBJJPrivateKeyNotAvailable)
+}
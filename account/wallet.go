@@ -0,0 +1,126 @@
+package account
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+const redacted = "[REDACTED]"
+
+// bjjWalletJSON is the JSON representation of a BJJWallet, without its private keys
+type bjjWalletJSON struct {
+	EthereumAddress              common.Address        `json:"ethereumAddress"`
+	HezEthAddress                string                `json:"hezEthereumAddress"`
+	HezBjjAddress                string                `json:"hezBjjAddress"`
+	PublicKey                    babyjub.PublicKeyComp `json:"publicKey"`
+	AccountCreationAuthSignature string                `json:"accountCreationAuthSignature,omitempty"`
+}
+
+// String returns the wallet addresses, with the private keys redacted
+func (w BJJWallet) String() string {
+	return fmt.Sprintf("BJJWallet{HezEthAddress: %s, HezBjjAddress: %s, PublicKey: %s, AccountCreationAuthSignature: %s, PrivateKey: %s, EthPrivateKey: %s}",
+		w.HezEthAddress, w.HezBjjAddress, w.PublicKey.String(), w.AccountCreationAuthSignature, redacted, redacted)
+}
+
+// GoString is String, so %#v doesn't print the private keys either
+func (w BJJWallet) GoString() string {
+	return w.String()
+}
+
+// Format implements fmt.Formatter, so every verb prints String and the private keys never reach logs
+func (w BJJWallet) Format(f fmt.State, verb rune) {
+	if verb == 'q' {
+		fmt.Fprintf(f, "%q", w.String())
+		return
+	}
+	fmt.Fprint(f, w.String())
+}
+
+// MarshalJSON marshals the wallet addresses and public key, without the private keys. The result can't be unmarshaled
+// into a usable BJJWallet, use EncryptBJJWallet to persist the keys
+func (w BJJWallet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bjjWalletJSON{
+		EthereumAddress:              w.EthAccount.Address,
+		HezEthAddress:                w.HezEthAddress,
+		HezBjjAddress:                w.HezBjjAddress,
+		PublicKey:                    w.PublicKey,
+		AccountCreationAuthSignature: w.AccountCreationAuthSignature,
+	})
+}
+
+// ExportBJJPrivateKey returns a copy of the Babyjubjub private key. It fails with ErrBJJPrivateKeyNotAvailable when
+// the wallet was destroyed
+func (w BJJWallet) ExportBJJPrivateKey() (bjjPvtKey babyjub.PrivateKey, err error) {
+	if !w.hasBJJPrivateKey() {
+		err = fmt.Errorf("[BJJWallet][ExportBJJPrivateKey] Account: %s - Error: %w", w.HezBjjAddress, ErrBJJPrivateKeyNotAvailable)
+		return
+	}
+	bjjPvtKey = *w.privateKey
+	return
+}
+
+// PrivateKey returns a copy of the Babyjubjub private key, nil when the wallet was destroyed. It replaces the
+// PrivateKey field, which was unexported so the key isn't printed or marshaled with the wallet.
+//
+// Deprecated: use ExportBJJPrivateKey, or sign through the BJJSigner methods of the wallet.
+func (w BJJWallet) PrivateKey() *babyjub.PrivateKey {
+	bjjPvtKey, err := w.ExportBJJPrivateKey()
+	if err != nil {
+		return nil
+	}
+	return &bjjPvtKey
+}
+
+// ExportEthPrivateKey returns a copy of the Ethereum private key. It fails with ErrEthPrivateKeyNotAvailable when the
+// wallet was not created from the Ethereum private key or was destroyed
+func (w BJJWallet) ExportEthPrivateKey() (ecdsaPvtKey *ecdsa.PrivateKey, err error) {
+	if !w.hasEthPrivateKey() {
+		err = fmt.Errorf("[BJJWallet][ExportEthPrivateKey] Account: %s - Error: %w", w.HezEthAddress, ErrEthPrivateKeyNotAvailable)
+		return
+	}
+	return crypto.ToECDSAUnsafe(crypto.FromECDSA(w.ethPrivateKey)), nil
+}
+
+// Destroy zeroes the private keys of the wallet. The keys are shared by every copy of the wallet, so none of them can
+// sign or export keys afterwards. Addresses and public key are kept
+func (w *BJJWallet) Destroy() {
+	if w.privateKey != nil {
+		for i := range w.privateKey {
+			w.privateKey[i] = 0
+		}
+	}
+	zeroEthPrivateKey(w.ethPrivateKey)
+	w.privateKey = nil
+	w.ethPrivateKey = nil
+}
+
+// zeroEthPrivateKey overwrites the secret of an Ethereum private key in place
+func zeroEthPrivateKey(ecdsaPvtKey *ecdsa.PrivateKey) {
+	if ecdsaPvtKey == nil || ecdsaPvtKey.D == nil {
+		return
+	}
+	words := ecdsaPvtKey.D.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	ecdsaPvtKey.D.SetInt64(0)
+}
+
+// hasBJJPrivateKey tells if the wallet holds a usable Babyjubjub private key
+func (w BJJWallet) hasBJJPrivateKey() bool {
+	if w.privateKey == nil {
+		return false
+	}
+	return *w.privateKey != babyjub.PrivateKey{}
+}
+
+// hasEthPrivateKey tells if the wallet holds a usable Ethereum private key
+func (w BJJWallet) hasEthPrivateKey() bool {
+	return w.ethPrivateKey != nil && w.ethPrivateKey.D != nil && w.ethPrivateKey.D.Cmp(big.NewInt(0)) != 0
+}
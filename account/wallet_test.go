@@ -0,0 +1,184 @@
+package account
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/hermeznetwork/hermez-go-sdk/internal/testutil"
+)
+
+const (
+	testChainID         = 5
+	testRollupAddress   = "0x679b11E0229959C1D3D27C9d20529E4C5DF7997c"
+	testKeyStorePasswd  = "wallet-test"
+	testWrongPassphrase = "not-the-password"
+)
+
+// walletSecrets returns the encodings of the private keys of the wallet that must never be printed
+func walletSecrets(t *testing.T, w BJJWallet) (secrets []string) {
+	bjjPvtKey, err := w.ExportBJJPrivateKey()
+	if err != nil {
+		t.Fatalf("ExportBJJPrivateKey: %s", err)
+	}
+	secrets = append(secrets,
+		hex.EncodeToString(bjjPvtKey[:]),
+		fmt.Sprint(bjjPvtKey[:]),
+		bjjPvtKey.Scalar().BigInt().String(),
+	)
+	if w.hasEthPrivateKey() {
+		ethPvtKey, err := w.ExportEthPrivateKey()
+		if err != nil {
+			t.Fatalf("ExportEthPrivateKey: %s", err)
+		}
+		secrets = append(secrets,
+			hex.EncodeToString(crypto.FromECDSA(ethPvtKey)),
+			ethPvtKey.D.String(),
+		)
+	}
+	return
+}
+
+func assertNoSecrets(t *testing.T, name string, output string, secrets []string) {
+	t.Helper()
+	lower := strings.ToLower(output)
+	for _, secret := range secrets {
+		if strings.Contains(lower, strings.ToLower(secret)) {
+			t.Errorf("%s leaks a private key: %s", name, output)
+		}
+	}
+}
+
+// newTestWallet creates the wallet of testutil.PvtKeyA with its account creation signature
+func newTestWallet(t *testing.T) BJJWallet {
+	wallet, _, err := CreateBjjWalletWithAccCreationSignatureFromHexPvtKey(testutil.PvtKeyA, testChainID, testRollupAddress)
+	if err != nil {
+		t.Fatalf("creating wallet: %s", err)
+	}
+	return wallet
+}
+
+func TestBJJWalletFormattingHidesPrivateKeys(t *testing.T) {
+	wallet := newTestWallet(t)
+	secrets := walletSecrets(t, wallet)
+	nested := struct {
+		Wallet  BJJWallet
+		Wallets []BJJWallet
+		Pointer *BJJWallet
+	}{wallet, []BJJWallet{wallet}, &wallet}
+
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x"} {
+		assertNoSecrets(t, verb, fmt.Sprintf(verb, wallet), secrets)
+		assertNoSecrets(t, verb+" pointer", fmt.Sprintf(verb, &wallet), secrets)
+		assertNoSecrets(t, verb+" nested", fmt.Sprintf(verb, nested), secrets)
+	}
+	for name, v := range map[string]interface{}{"wallet": wallet, "pointer": &wallet, "nested": nested} {
+		marshaled, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("json.Marshal %s: %s", name, err)
+		}
+		assertNoSecrets(t, "json.Marshal "+name, string(marshaled), secrets)
+	}
+	if !strings.Contains(wallet.String(), wallet.HezBjjAddress) {
+		t.Errorf("String doesn't show the wallet address: %s", wallet.String())
+	}
+}
+
+func TestSDKLogsHidePrivateKeys(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	wallet := newTestWallet(t)
+	mnemonic, err := NewMnemonic(128)
+	if err != nil {
+		t.Fatalf("NewMnemonic: %s", err)
+	}
+	hdWallet, _, err := CreateBjjWalletFromMnemonic(mnemonic)
+	if err != nil {
+		t.Fatalf("CreateBjjWalletFromMnemonic: %s", err)
+	}
+	secrets := append(walletSecrets(t, wallet), walletSecrets(t, hdWallet)...)
+	secrets = append(secrets, mnemonic)
+
+	// the success paths and the error paths that log
+	if _, err = NewAccountCreation(wallet); err != nil {
+		t.Fatalf("NewAccountCreation: %s", err)
+	}
+	if _, _, err = CreateBjjWalletFromHexPvtKey("zz" + testutil.PvtKeyA[2:]); err == nil {
+		t.Fatal("CreateBjjWalletFromHexPvtKey accepted an invalid key")
+	}
+	if _, _, err = CreateBjjWalletFromMnemonicWithPath(mnemonic, "m/invalid"); err == nil {
+		t.Fatal("CreateBjjWalletFromMnemonicWithPath accepted an invalid path")
+	}
+	keyJSON, err := EncryptBJJWallet(wallet, testKeyStorePasswd, keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatalf("EncryptBJJWallet: %s", err)
+	}
+	if _, err = DecryptBJJWallet(keyJSON, testWrongPassphrase); err == nil {
+		t.Fatal("DecryptBJJWallet accepted a wrong password")
+	}
+	decrypted, err := DecryptBJJWallet(keyJSON, testKeyStorePasswd)
+	if err != nil {
+		t.Fatalf("DecryptBJJWallet: %s", err)
+	}
+	log.Printf("wallets: %v %+v %#v\n", wallet, hdWallet, decrypted)
+
+	if logs.Len() == 0 {
+		t.Fatal("no SDK log output captured")
+	}
+	assertNoSecrets(t, "SDK log output", logs.String(), secrets)
+}
+
+func TestCreateBjjWalletFromEthKeyStoreJSONKeepsItsKey(t *testing.T) {
+	ecdsaPvtKey, err := crypto.HexToECDSA(testutil.PvtKeyA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &keystore.Key{Address: crypto.PubkeyToAddress(ecdsaPvtKey.PublicKey), PrivateKey: ecdsaPvtKey}
+	keyJSON, err := keystore.EncryptKey(key, testKeyStorePasswd, keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatalf("EncryptKey: %s", err)
+	}
+
+	// the decrypted key is zeroed, the wallet must still hold a usable copy
+	wallet, _, err := CreateBjjWalletFromEthKeyStoreJSON(keyJSON, testKeyStorePasswd, testChainID, testRollupAddress)
+	if err != nil {
+		t.Fatalf("CreateBjjWalletFromEthKeyStoreJSON: %s", err)
+	}
+	ethPvtKey, err := wallet.ExportEthPrivateKey()
+	if err != nil {
+		t.Fatalf("ExportEthPrivateKey: %s", err)
+	}
+	if hex.EncodeToString(crypto.FromECDSA(ethPvtKey)) != testutil.PvtKeyA {
+		t.Error("wallet created from the keystore doesn't hold the Ethereum private key")
+	}
+	if _, err = wallet.SignHash(crypto.Keccak256([]byte("hermez"))); err != nil {
+		t.Errorf("SignHash: %s", err)
+	}
+}
+
+func TestZeroEthPrivateKey(t *testing.T) {
+	ecdsaPvtKey, err := crypto.HexToECDSA(testutil.PvtKeyA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := ecdsaPvtKey.D.Bits()
+	zeroEthPrivateKey(ecdsaPvtKey)
+	for _, word := range words {
+		if word != 0 {
+			t.Fatal("zeroEthPrivateKey left the private key in memory")
+		}
+	}
+	if ecdsaPvtKey.D.Sign() != 0 {
+		t.Errorf("zeroed private key: %s", ecdsaPvtKey.D)
+	}
+	zeroEthPrivateKey(nil)
+}
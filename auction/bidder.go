@@ -0,0 +1,430 @@
+package auction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	HermezAuctionProtocol "github.com/hermeznetwork/hermez-node/eth/contracts/auction"
+	"github.com/hermeznetwork/hermez-node/eth/contracts/tokenhez"
+)
+
+// DefaultPermitValidity is how long the HEZ permits signed for a bid are valid
+const DefaultPermitValidity = time.Hour
+
+var (
+	// ErrCoordinatorNotRegistered is returned when bidding from an address without coordinator, see SetCoordinator
+	ErrCoordinatorNotRegistered = errors.New("coordinator is not registered")
+	// ErrSlotNotInAuction is returned when bidding for a slot whose auction is closed or not open yet
+	ErrSlotNotInAuction = errors.New("slot auction is not open")
+	// ErrBidBelowMinimum is returned when the bid doesn't reach the min bid of the slot
+	ErrBidBelowMinimum = errors.New("bid is below the slot min bid")
+	// ErrBidAmountNotSet is returned when the amount of a bid, or the MinBid or MaxBid of a MultiBid, is nil
+	ErrBidAmountNotSet = errors.New("bid amount is not set")
+)
+
+// BidFunding tells how the HEZ of a bid are moved to the Auction contract
+type BidFunding int
+
+const (
+	// BidFundingPermit signs a HEZ permit sent together with the bid, no previous tx is needed
+	BidFundingPermit BidFunding = iota
+	// BidFundingApproval uses the allowance given to the Auction contract with ApproveHEZ
+	BidFundingApproval
+)
+
+// Bidder registers the coordinator of an Ethereum account and bids with it through the HermezAuctionProtocol.Auction
+// binding of the HermezClient. The txs are signed with the EthSigner and sent through backend.
+type Bidder struct {
+	hezClient      client.HermezClient
+	backend        bind.ContractBackend
+	signer         account.EthSigner
+	chainID        *big.Int
+	hez            *tokenhez.Tokenhez
+	hezAddress     ethCommon.Address
+	PermitValidity time.Duration
+	GasLimit       uint64
+}
+
+// MultiBid describes a bid for a range of slots. Deposit is the HEZ sent to the Auction contract, the bids are paid
+// from the pending balance of the bidder. Every slot of the range whose slot set is enabled gets a bid of its min
+// bid, but not lower than MinBid. The slots whose min bid is above MaxBid are skipped.
+type MultiBid struct {
+	StartingSlot int64
+	EndingSlot   int64
+	SlotSets     [numSlotSets]bool
+	MinBid       *big.Int
+	MaxBid       *big.Int
+	Deposit      *big.Int
+}
+
+// BidStatus is the bid of a slot seen by a bidder. Winning is true while the bidder has the best bid, MinBid is the
+// amount needed to outbid the best bid.
+type BidStatus struct {
+	SlotNum    int64
+	Status     SlotStatus
+	BestBidder ethCommon.Address
+	BestBid    *big.Int
+	MinBid     *big.Int
+	Winning    bool
+}
+
+// NewBidder binds the HEZ token of the Auction contract of the HermezClient. A nil backend uses
+// hezClient.EthClient.
+func NewBidder(hezClient client.HermezClient, backend bind.ContractBackend, signer account.EthSigner) (bidder *Bidder, err error) {
+	if hezClient.AuctionContract == nil {
+		err = fmt.Errorf("[Auction][NewBidder] Error: %w", ErrAuctionContractNotSet)
+		return
+	}
+	if backend == nil {
+		backend = hezClient.EthClient
+	}
+	bidder = &Bidder{
+		hezClient:      hezClient,
+		backend:        backend,
+		signer:         signer,
+		chainID:        big.NewInt(int64(hezClient.EthereumChainID)),
+		PermitValidity: DefaultPermitValidity,
+	}
+	if bidder.hezAddress, err = hezClient.AuctionContract.TokenHEZ(nil); err != nil {
+		err = fmt.Errorf("[Auction][NewBidder] Error reading HEZ token address - Error: %s", err.Error())
+		return nil, err
+	}
+	if bidder.hez, err = tokenhez.NewTokenhez(bidder.hezAddress, backend); err != nil {
+		err = fmt.Errorf("[Auction][NewBidder] Error binding HEZ token - Error: %s", err.Error())
+		return nil, err
+	}
+	return
+}
+
+// transactOpts signs the txs with the EthSigner of the bidder
+func (b *Bidder) transactOpts(ctx context.Context) *bind.TransactOpts {
+	txSigner := types.LatestSignerForChainID(b.chainID)
+	return &bind.TransactOpts{
+		From:     b.signer.EthAddress(),
+		Context:  ctx,
+		GasLimit: b.GasLimit,
+		Signer: func(address ethCommon.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != b.signer.EthAddress() {
+				return nil, bind.ErrNotAuthorized
+			}
+			hash := txSigner.Hash(tx)
+			signature, err := b.signer.SignHash(hash[:])
+			if err != nil {
+				return nil, err
+			}
+			return tx.WithSignature(txSigner, signature)
+		},
+	}
+}
+
+// SetCoordinator registers, or updates, the forger address and URL of the coordinator of the bidder
+func (b *Bidder) SetCoordinator(ctx context.Context, forger ethCommon.Address, coordinatorURL string) (tx *types.Transaction, err error) {
+	tx, err = b.hezClient.AuctionContract.SetCoordinator(b.transactOpts(ctx), forger, coordinatorURL)
+	if err != nil {
+		err = fmt.Errorf("[Auction][SetCoordinator] Forger: %s - URL: %s - Error: %s", forger.Hex(), coordinatorURL, err.Error())
+		return
+	}
+	return
+}
+
+// ApproveHEZ allows the Auction contract to take amount HEZ from the bidder, for the bids funded with
+// BidFundingApproval
+func (b *Bidder) ApproveHEZ(ctx context.Context, amount *big.Int) (tx *types.Transaction, err error) {
+	tx, err = b.hez.Approve(b.transactOpts(ctx), b.hezClient.AuctionContractAddress, amount)
+	if err != nil {
+		err = fmt.Errorf("[Auction][ApproveHEZ] Amount: %s - Error: %s", amount.String(), err.Error())
+		return
+	}
+	return
+}
+
+// HEZPermit signs a permit for the Auction contract to take amount HEZ from the bidder until deadline, encoded as the
+// permit call the Auction contract expects
+func (b *Bidder) HEZPermit(amount *big.Int, deadline time.Time) (permit []byte, err error) {
+	owner := b.signer.EthAddress()
+	spender := b.hezClient.AuctionContractAddress
+	nonce, err := b.hez.Nonces(nil, owner)
+	if err != nil {
+		err = fmt.Errorf("[Auction][HEZPermit] Error reading permit nonce - Error: %s", err.Error())
+		return
+	}
+	domainSeparator, err := b.hezDomainSeparator()
+	if err != nil {
+		err = fmt.Errorf("[Auction][HEZPermit] Error: %w", err)
+		return
+	}
+	permitTypeHash, err := b.hez.PERMITTYPEHASH(nil)
+	if err != nil {
+		err = fmt.Errorf("[Auction][HEZPermit] Error reading permit type hash - Error: %s", err.Error())
+		return
+	}
+	deadlineInt := big.NewInt(deadline.Unix())
+	structHash := crypto.Keccak256(permitTypeHash[:], pad32(owner.Bytes()), pad32(spender.Bytes()), pad32(amount.Bytes()),
+		pad32(nonce.Bytes()), pad32(deadlineInt.Bytes()))
+	digest := crypto.Keccak256([]byte{0x19, 0x01}, domainSeparator, structHash)
+	signature, err := b.signer.SignHash(digest)
+	if err != nil {
+		err = fmt.Errorf("[Auction][HEZPermit] Error signing permit - Error: %w", err)
+		return
+	}
+
+	methodID := crypto.Keccak256([]byte("permit(address,address,uint256,uint256,uint8,bytes32,bytes32)"))[:4]
+	permit = append(permit, methodID...)
+	permit = append(permit, pad32(owner.Bytes())...)
+	permit = append(permit, pad32(spender.Bytes())...)
+	permit = append(permit, pad32(amount.Bytes())...)
+	permit = append(permit, pad32(deadlineInt.Bytes())...)
+	permit = append(permit, pad32([]byte{signature[64] + 27})...)
+	permit = append(permit, signature[:64]...)
+	return
+}
+
+// hezDomainSeparator builds the EIP-712 domain separator of the HEZ token from the hashes it exposes
+func (b *Bidder) hezDomainSeparator() (domainSeparator []byte, err error) {
+	domainHash, err := b.hez.EIP712DOMAINHASH(nil)
+	if err != nil {
+		err = fmt.Errorf("[Auction][hezDomainSeparator] Error reading domain hash - Error: %s", err.Error())
+		return
+	}
+	nameHash, err := b.hez.NAMEHASH(nil)
+	if err != nil {
+		err = fmt.Errorf("[Auction][hezDomainSeparator] Error reading name hash - Error: %s", err.Error())
+		return
+	}
+	versionHash, err := b.hez.VERSIONHASH(nil)
+	if err != nil {
+		err = fmt.Errorf("[Auction][hezDomainSeparator] Error reading version hash - Error: %s", err.Error())
+		return
+	}
+	chainID, err := b.hez.GetChainId(nil)
+	if err != nil {
+		err = fmt.Errorf("[Auction][hezDomainSeparator] Error reading chain ID - Error: %s", err.Error())
+		return
+	}
+	domainSeparator = crypto.Keccak256(domainHash[:], nameHash[:], versionHash[:], pad32(chainID.Bytes()), pad32(b.hezAddress.Bytes()))
+	return
+}
+
+func pad32(b []byte) []byte {
+	return ethCommon.LeftPadBytes(b, 32)
+}
+
+// Bid bids bidAmount HEZ for the slot. The HEZ missing in the pending balance of the bidder are moved to the Auction
+// contract as funding says.
+func (b *Bidder) Bid(ctx context.Context, slotNum int64, bidAmount *big.Int, funding BidFunding) (tx *types.Transaction, err error) {
+	if bidAmount == nil {
+		err = fmt.Errorf("[Auction][Bid] Slot: %d - Error: %w", slotNum, ErrBidAmountNotSet)
+		return
+	}
+	if err = b.checkCoordinator(); err != nil {
+		err = fmt.Errorf("[Auction][Bid] Slot: %d - Error: %w", slotNum, err)
+		return
+	}
+	state, err := GetState(b.hezClient)
+	if err != nil {
+		err = fmt.Errorf("[Auction][Bid] Slot: %d - Error: %w", slotNum, err)
+		return
+	}
+	if state.SlotStatus(slotNum) != SlotStatusOpen {
+		err = fmt.Errorf("[Auction][Bid] Slot: %d - Error: %w", slotNum, ErrSlotNotInAuction)
+		return
+	}
+	minBid, err := GetMinBidBySlot(b.hezClient, slotNum)
+	if err != nil {
+		err = fmt.Errorf("[Auction][Bid] Error: %w", err)
+		return
+	}
+	if bidAmount.Cmp(minBid) < 0 {
+		err = fmt.Errorf("[Auction][Bid] Slot: %d - Bid: %s - Min bid: %s - Error: %w", slotNum, bidAmount.String(), minBid.String(), ErrBidBelowMinimum)
+		return
+	}
+	pendingBalance, err := b.hezClient.AuctionContract.PendingBalances(nil, b.signer.EthAddress())
+	if err != nil {
+		err = fmt.Errorf("[Auction][Bid] Error reading pending balance - Error: %s", err.Error())
+		return
+	}
+	deposit := new(big.Int).Sub(bidAmount, pendingBalance)
+	if deposit.Sign() < 0 {
+		deposit.SetInt64(0)
+	}
+	permit, err := b.funding(deposit, funding)
+	if err != nil {
+		err = fmt.Errorf("[Auction][Bid] Slot: %d - Error: %w", slotNum, err)
+		return
+	}
+	tx, err = b.hezClient.AuctionContract.ProcessBid(b.transactOpts(ctx), deposit, big.NewInt(slotNum), bidAmount, permit)
+	if err != nil {
+		err = fmt.Errorf("[Auction][Bid] Slot: %d - Bid: %s - Error: %s", slotNum, bidAmount.String(), err.Error())
+		return
+	}
+	return
+}
+
+// MultiBid bids for a range of slots in a single tx, see MultiBid
+func (b *Bidder) MultiBid(ctx context.Context, multiBid MultiBid, funding BidFunding) (tx *types.Transaction, err error) {
+	if multiBid.MinBid == nil || multiBid.MaxBid == nil {
+		err = fmt.Errorf("[Auction][MultiBid] Slots: %d-%d - Error: %w", multiBid.StartingSlot, multiBid.EndingSlot, ErrBidAmountNotSet)
+		return
+	}
+	if err = b.checkCoordinator(); err != nil {
+		err = fmt.Errorf("[Auction][MultiBid] Slots: %d-%d - Error: %w", multiBid.StartingSlot, multiBid.EndingSlot, err)
+		return
+	}
+	state, err := GetState(b.hezClient)
+	if err != nil {
+		err = fmt.Errorf("[Auction][MultiBid] Error: %w", err)
+		return
+	}
+	if state.SlotStatus(multiBid.StartingSlot) != SlotStatusOpen || state.SlotStatus(multiBid.EndingSlot) != SlotStatusOpen {
+		err = fmt.Errorf("[Auction][MultiBid] Slots: %d-%d - Error: %w", multiBid.StartingSlot, multiBid.EndingSlot, ErrSlotNotInAuction)
+		return
+	}
+	deposit := multiBid.Deposit
+	if deposit == nil {
+		deposit = big.NewInt(0)
+	}
+	permit, err := b.funding(deposit, funding)
+	if err != nil {
+		err = fmt.Errorf("[Auction][MultiBid] Error: %w", err)
+		return
+	}
+	tx, err = b.hezClient.AuctionContract.ProcessMultiBid(b.transactOpts(ctx), deposit, big.NewInt(multiBid.StartingSlot),
+		big.NewInt(multiBid.EndingSlot), multiBid.SlotSets, multiBid.MaxBid, multiBid.MinBid, permit)
+	if err != nil {
+		err = fmt.Errorf("[Auction][MultiBid] Slots: %d-%d - Error: %s", multiBid.StartingSlot, multiBid.EndingSlot, err.Error())
+		return
+	}
+	return
+}
+
+// funding returns the permit to send with a bid that moves deposit HEZ, empty when it's not needed
+func (b *Bidder) funding(deposit *big.Int, funding BidFunding) (permit []byte, err error) {
+	if funding != BidFundingPermit || deposit.Sign() == 0 {
+		return
+	}
+	return b.HEZPermit(deposit, time.Now().Add(b.PermitValidity))
+}
+
+// checkCoordinator fails when the bidder has no coordinator registered
+func (b *Bidder) checkCoordinator() (err error) {
+	coordinator, err := b.hezClient.AuctionContract.Coordinators(nil, b.signer.EthAddress())
+	if err != nil {
+		err = fmt.Errorf("[Auction][checkCoordinator] Error reading coordinator - Error: %s", err.Error())
+		return
+	}
+	if coordinator.Forger == (ethCommon.Address{}) {
+		err = fmt.Errorf("[Auction][checkCoordinator] Bidder: %s - Error: %w", b.signer.EthAddress().Hex(), ErrCoordinatorNotRegistered)
+		return
+	}
+	return
+}
+
+// ClaimHEZ sends to the bidder the HEZ of its outbid bids
+func (b *Bidder) ClaimHEZ(ctx context.Context) (tx *types.Transaction, err error) {
+	tx, err = b.hezClient.AuctionContract.ClaimHEZ(b.transactOpts(ctx))
+	if err != nil {
+		err = fmt.Errorf("[Auction][ClaimHEZ] Bidder: %s - Error: %s", b.signer.EthAddress().Hex(), err.Error())
+		return
+	}
+	return
+}
+
+// GetCoordinator reads the forger address and URL registered by a bidder. registered is false when it has none.
+func GetCoordinator(hezClient client.HermezClient, bidder ethCommon.Address) (forger ethCommon.Address, coordinatorURL string, registered bool, err error) {
+	if hezClient.AuctionContract == nil {
+		err = fmt.Errorf("[Auction][GetCoordinator] Error: %w", ErrAuctionContractNotSet)
+		return
+	}
+	coordinator, err := hezClient.AuctionContract.Coordinators(nil, bidder)
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetCoordinator] Bidder: %s - Error: %s", bidder.Hex(), err.Error())
+		return
+	}
+	return coordinator.Forger, coordinator.CoordinatorURL, coordinator.Forger != (ethCommon.Address{}), nil
+}
+
+// GetClaimableHEZ reads the HEZ of the outbid bids the bidder can claim with ClaimHEZ
+func GetClaimableHEZ(hezClient client.HermezClient, bidder ethCommon.Address) (claimable *big.Int, err error) {
+	if hezClient.AuctionContract == nil {
+		err = fmt.Errorf("[Auction][GetClaimableHEZ] Error: %w", ErrAuctionContractNotSet)
+		return
+	}
+	claimable, err = hezClient.AuctionContract.GetClaimableHEZ(nil, bidder)
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetClaimableHEZ] Bidder: %s - Error: %s", bidder.Hex(), err.Error())
+		return
+	}
+	return
+}
+
+// GetBidStatus reads from the Auction contract the best bid of the slot and whether bidder has it
+func GetBidStatus(hezClient client.HermezClient, bidder ethCommon.Address, slotNum int64) (status BidStatus, err error) {
+	state, err := GetState(hezClient)
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetBidStatus] Error: %w", err)
+		return
+	}
+	return bidStatus(hezClient.AuctionContract, state, bidder, slotNum)
+}
+
+// GetOutbidSlots returns the slots from fromSlot on where bidder bid, according to the bids of the boot coordinator,
+// and someone else has now the best bid
+func GetOutbidSlots(hezClient client.HermezClient, bidder ethCommon.Address, fromSlot int64) (outbid []BidStatus, err error) {
+	bids, err := GetAllBids(hezClient, BidFilter{BidderAddr: &bidder})
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetOutbidSlots] Error: %w", err)
+		return
+	}
+	state, err := GetState(hezClient)
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetOutbidSlots] Error: %w", err)
+		return
+	}
+	checked := map[int64]bool{}
+	for _, bid := range bids {
+		if bid.SlotNum < fromSlot || checked[bid.SlotNum] {
+			continue
+		}
+		checked[bid.SlotNum] = true
+		var status BidStatus
+		if status, err = bidStatus(hezClient.AuctionContract, state, bidder, bid.SlotNum); err != nil {
+			err = fmt.Errorf("[Auction][GetOutbidSlots] Error: %w", err)
+			return
+		}
+		if !status.Winning {
+			outbid = append(outbid, status)
+		}
+	}
+	return
+}
+
+func bidStatus(contract *HermezAuctionProtocol.Auction, state State, bidder ethCommon.Address, slotNum int64) (status BidStatus, err error) {
+	slot, err := contract.Slots(nil, big.NewInt(slotNum))
+	if err != nil {
+		err = fmt.Errorf("[Auction][bidStatus] Error reading slot %d - Error: %s", slotNum, err.Error())
+		return
+	}
+	status = BidStatus{
+		SlotNum:    slotNum,
+		Status:     state.SlotStatus(slotNum),
+		BestBidder: slot.Bidder,
+		BestBid:    slot.BidAmount,
+		Winning:    slot.Bidder == bidder,
+	}
+	if status.Status == SlotStatusOpen {
+		if status.MinBid, err = contract.GetMinBidBySlot(nil, big.NewInt(slotNum)); err != nil {
+			err = fmt.Errorf("[Auction][bidStatus] Error reading min bid of slot %d - Error: %s", slotNum, err.Error())
+			return
+		}
+	}
+	return
+}
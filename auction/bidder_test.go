@@ -0,0 +1,338 @@
+package auction
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dghubble/sling"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-go-sdk/internal/testutil"
+	"github.com/hermeznetwork/hermez-go-sdk/internal/testwallet"
+	"github.com/hermeznetwork/hermez-node/common/apitypes"
+	HermezAuctionProtocol "github.com/hermeznetwork/hermez-node/eth/contracts/auction"
+	"github.com/hermeznetwork/hermez-node/eth/contracts/tokenhez"
+)
+
+const (
+	testDeployerKey = "b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291"
+	// testChainID is the chain ID of the simulated backend
+	testChainID = 1337
+)
+
+var testHEZ = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+func hez(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), testHEZ)
+}
+
+// testAuction is an Auction contract and its HEZ token deployed on a simulated backend
+type testAuction struct {
+	sim       *backends.SimulatedBackend
+	hezClient client.HermezClient
+	hez       *tokenhez.Tokenhez
+	bidderA   account.BJJWallet
+	bidderB   account.BJJWallet
+}
+
+// readTestBytecode reads the bytecode of a contract, copied from the eth/contracts/abi artifacts of hermez-node
+func readTestBytecode(t *testing.T, name string) []byte {
+	data, err := ioutil.ReadFile("testdata/" + name + ".bin")
+	if err != nil {
+		t.Fatalf("reading %s bytecode: %s", name, err)
+	}
+	bytecode, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("decoding %s bytecode: %s", name, err)
+	}
+	return bytecode
+}
+
+func mined(t *testing.T, sim *backends.SimulatedBackend, tx *types.Transaction, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("sending tx: %s", err)
+	}
+	sim.Commit()
+	receipt, err := sim.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		t.Fatalf("reading receipt: %s", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("tx %s reverted", tx.Hash().Hex())
+	}
+}
+
+// deployTestAuction deploys HEZ and the Auction contract, gives 100 HEZ to each bidder and mines until the slot 0
+func deployTestAuction(t *testing.T) *testAuction {
+	deployerKey, err := crypto.HexToECDSA(testDeployerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployer := crypto.PubkeyToAddress(deployerKey.PublicKey)
+	ta := &testAuction{
+		bidderA: testwallet.New(t, testutil.PvtKeyA),
+		bidderB: testwallet.New(t, testutil.PvtKeyB),
+	}
+	balance := new(big.Int).Mul(big.NewInt(1000), testHEZ)
+	ta.sim = backends.NewSimulatedBackend(core.GenesisAlloc{
+		deployer:                      {Balance: balance},
+		ta.bidderA.EthAccount.Address: {Balance: balance},
+		ta.bidderB.EthAccount.Address: {Balance: balance},
+	}, 30000000)
+	t.Cleanup(func() { ta.sim.Close() })
+	opts, err := bind.NewKeyedTransactorWithChainID(deployerKey, big.NewInt(testChainID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hezABI, err := abi.JSON(strings.NewReader(tokenhez.TokenhezABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hezAddress, tx, _, err := bind.DeployContract(opts, hezABI, readTestBytecode(t, "HEZ"), ta.sim, deployer)
+	mined(t, ta.sim, tx, err)
+	if ta.hez, err = tokenhez.NewTokenhez(hezAddress, ta.sim); err != nil {
+		t.Fatal(err)
+	}
+	for _, bidder := range []account.BJJWallet{ta.bidderA, ta.bidderB} {
+		tx, err = ta.hez.Transfer(opts, bidder.EthAccount.Address, hez(100))
+		mined(t, ta.sim, tx, err)
+	}
+
+	auctionABI, err := abi.JSON(strings.NewReader(HermezAuctionProtocol.AuctionABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	auctionAddress, tx, _, err := bind.DeployContract(opts, auctionABI, readTestBytecode(t, "HermezAuctionProtocol"), ta.sim)
+	mined(t, ta.sim, tx, err)
+	auctionContract, err := HermezAuctionProtocol.NewAuction(auctionAddress, ta.sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesisBlock := ta.sim.Blockchain().CurrentBlock().NumberU64() + 2
+	tx, err = auctionContract.HermezAuctionProtocolInitializer(opts, hezAddress, new(big.Int).SetUint64(genesisBlock),
+		ethCommon.HexToAddress("0x0000000000000000000000000000000000000001"), deployer, deployer, deployer, "https://boot.coordinator")
+	mined(t, ta.sim, tx, err)
+	// the default min bids are too high for the test balances
+	for slotSet := 0; slotSet < numSlotSets; slotSet++ {
+		tx, err = auctionContract.ChangeDefaultSlotSetBid(opts, big.NewInt(int64(slotSet)), hez(10))
+		mined(t, ta.sim, tx, err)
+	}
+	for ta.sim.Blockchain().CurrentBlock().NumberU64() < genesisBlock {
+		ta.sim.Commit()
+	}
+
+	ta.hezClient = client.HermezClient{
+		AuctionContract:        auctionContract,
+		AuctionContractAddress: auctionAddress,
+		EthereumChainID:        testChainID,
+	}
+	return ta
+}
+
+func (ta *testAuction) newBidder(t *testing.T, wallet account.BJJWallet) *Bidder {
+	bidder, err := NewBidder(ta.hezClient, ta.sim, wallet)
+	if err != nil {
+		t.Fatalf("NewBidder: %s", err)
+	}
+	return bidder
+}
+
+func (ta *testAuction) registerCoordinator(t *testing.T, bidder *Bidder, wallet account.BJJWallet) {
+	tx, err := bidder.SetCoordinator(context.Background(), wallet.EthAccount.Address, "https://"+wallet.EthAccount.Address.Hex())
+	mined(t, ta.sim, tx, err)
+}
+
+func (ta *testAuction) hezBalance(t *testing.T, address ethCommon.Address) *big.Int {
+	balance, err := ta.hez.BalanceOf(nil, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return balance
+}
+
+func TestSetCoordinator(t *testing.T) {
+	ta := deployTestAuction(t)
+	bidder := ta.newBidder(t, ta.bidderA)
+	ctx := context.Background()
+
+	_, err := bidder.Bid(ctx, 5, hez(11), BidFundingPermit)
+	if !errors.Is(err, ErrCoordinatorNotRegistered) {
+		t.Fatalf("bid without coordinator: %v", err)
+	}
+	ta.registerCoordinator(t, bidder, ta.bidderA)
+	forger, coordinatorURL, registered, err := GetCoordinator(ta.hezClient, ta.bidderA.EthAccount.Address)
+	if err != nil {
+		t.Fatalf("GetCoordinator: %s", err)
+	}
+	if !registered || forger != ta.bidderA.EthAccount.Address || coordinatorURL != "https://"+forger.Hex() {
+		t.Fatalf("coordinator not registered: %s %s %t", forger.Hex(), coordinatorURL, registered)
+	}
+}
+
+func TestBidWithPermit(t *testing.T) {
+	ta := deployTestAuction(t)
+	bidder := ta.newBidder(t, ta.bidderA)
+	ta.registerCoordinator(t, bidder, ta.bidderA)
+	before := ta.hezBalance(t, ta.bidderA.EthAccount.Address)
+
+	tx, err := bidder.Bid(context.Background(), 5, hez(11), BidFundingPermit)
+	mined(t, ta.sim, tx, err)
+	status, err := GetBidStatus(ta.hezClient, ta.bidderA.EthAccount.Address, 5)
+	if err != nil {
+		t.Fatalf("GetBidStatus: %s", err)
+	}
+	if !status.Winning || status.BestBid.Cmp(hez(11)) != 0 {
+		t.Fatalf("bid not placed: %+v", status)
+	}
+	if spent := new(big.Int).Sub(before, ta.hezBalance(t, ta.bidderA.EthAccount.Address)); spent.Cmp(hez(11)) != 0 {
+		t.Fatalf("permit moved %s HEZ, expected %s", spent, hez(11))
+	}
+	// the permit nonce was used, a second permit must be signed with the next one
+	tx, err = bidder.Bid(context.Background(), 6, hez(11), BidFundingPermit)
+	mined(t, ta.sim, tx, err)
+}
+
+func TestBidWithApproval(t *testing.T) {
+	ta := deployTestAuction(t)
+	bidder := ta.newBidder(t, ta.bidderA)
+	ta.registerCoordinator(t, bidder, ta.bidderA)
+	ctx := context.Background()
+
+	tx, err := bidder.ApproveHEZ(ctx, hez(11))
+	mined(t, ta.sim, tx, err)
+	tx, err = bidder.Bid(ctx, 5, hez(11), BidFundingApproval)
+	mined(t, ta.sim, tx, err)
+	status, err := GetBidStatus(ta.hezClient, ta.bidderA.EthAccount.Address, 5)
+	if err != nil {
+		t.Fatalf("GetBidStatus: %s", err)
+	}
+	if !status.Winning {
+		t.Fatalf("bid not placed: %+v", status)
+	}
+}
+
+func TestBidChecks(t *testing.T) {
+	ta := deployTestAuction(t)
+	bidder := ta.newBidder(t, ta.bidderA)
+	ta.registerCoordinator(t, bidder, ta.bidderA)
+	ctx := context.Background()
+
+	if _, err := bidder.Bid(ctx, 5, nil, BidFundingPermit); !errors.Is(err, ErrBidAmountNotSet) {
+		t.Errorf("nil bid: %v", err)
+	}
+	if _, err := bidder.MultiBid(ctx, MultiBid{StartingSlot: 5, EndingSlot: 6, MaxBid: hez(20)}, BidFundingPermit); !errors.Is(err, ErrBidAmountNotSet) {
+		t.Errorf("nil MinBid: %v", err)
+	}
+	if _, err := bidder.MultiBid(ctx, MultiBid{StartingSlot: 5, EndingSlot: 6, MinBid: hez(10)}, BidFundingPermit); !errors.Is(err, ErrBidAmountNotSet) {
+		t.Errorf("nil MaxBid: %v", err)
+	}
+	if _, err := bidder.Bid(ctx, 1, hez(11), BidFundingPermit); !errors.Is(err, ErrSlotNotInAuction) {
+		t.Errorf("closed slot: %v", err)
+	}
+	if _, err := bidder.Bid(ctx, 5, hez(1), BidFundingPermit); !errors.Is(err, ErrBidBelowMinimum) {
+		t.Errorf("low bid: %v", err)
+	}
+}
+
+func TestMultiBid(t *testing.T) {
+	ta := deployTestAuction(t)
+	bidder := ta.newBidder(t, ta.bidderA)
+	ta.registerCoordinator(t, bidder, ta.bidderA)
+
+	multiBid := MultiBid{
+		StartingSlot: 5,
+		EndingSlot:   8,
+		SlotSets:     [numSlotSets]bool{true, true, true, true, true, true},
+		MinBid:       hez(10),
+		MaxBid:       hez(20),
+		Deposit:      hez(50),
+	}
+	tx, err := bidder.MultiBid(context.Background(), multiBid, BidFundingPermit)
+	mined(t, ta.sim, tx, err)
+	for slot := multiBid.StartingSlot; slot <= multiBid.EndingSlot; slot++ {
+		status, err := GetBidStatus(ta.hezClient, ta.bidderA.EthAccount.Address, slot)
+		if err != nil {
+			t.Fatalf("GetBidStatus: %s", err)
+		}
+		if !status.Winning {
+			t.Errorf("slot %d not won: %+v", slot, status)
+		}
+	}
+}
+
+func TestOutbidAndClaimHEZ(t *testing.T) {
+	ta := deployTestAuction(t)
+	bidderA := ta.newBidder(t, ta.bidderA)
+	bidderB := ta.newBidder(t, ta.bidderB)
+	ta.registerCoordinator(t, bidderA, ta.bidderA)
+	ta.registerCoordinator(t, bidderB, ta.bidderB)
+	ctx := context.Background()
+	addrA := ta.bidderA.EthAccount.Address
+
+	tx, err := bidderA.Bid(ctx, 5, hez(11), BidFundingPermit)
+	mined(t, ta.sim, tx, err)
+	tx, err = bidderA.Bid(ctx, 6, hez(11), BidFundingPermit)
+	mined(t, ta.sim, tx, err)
+	tx, err = bidderB.Bid(ctx, 5, hez(13), BidFundingPermit)
+	mined(t, ta.sim, tx, err)
+
+	// the boot coordinator lists the bids of A, the contract tells which ones were outbid
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/bids" || r.URL.Query().Get("bidderAddr") != addrA.Hex() {
+			http.NotFound(w, r)
+			return
+		}
+		testutil.WriteJSON(w, BidsAPIResponse{Bids: []Bid{
+			{ItemID: 1, SlotNum: 5, BidValue: apitypes.BigIntStr(hez(11).String()), Bidder: addrA, Forger: addrA},
+			{ItemID: 2, SlotNum: 6, BidValue: apitypes.BigIntStr(hez(11).String()), Bidder: addrA, Forger: addrA},
+		}})
+	}))
+	defer node.Close()
+	hezClient := ta.hezClient
+	hezClient.BootCoordinatorURL = node.URL
+	hezClient.BootCoordinatorClient = sling.New().Base(node.URL).Client(node.Client())
+
+	outbid, err := GetOutbidSlots(hezClient, addrA, 0)
+	if err != nil {
+		t.Fatalf("GetOutbidSlots: %s", err)
+	}
+	if len(outbid) != 1 || outbid[0].SlotNum != 5 || outbid[0].BestBidder != ta.bidderB.EthAccount.Address {
+		t.Fatalf("outbid slots: %+v", outbid)
+	}
+	if outbid[0].MinBid == nil || outbid[0].MinBid.Cmp(hez(13)) <= 0 {
+		t.Errorf("min bid to outbid: %v", outbid[0].MinBid)
+	}
+
+	claimable, err := GetClaimableHEZ(ta.hezClient, addrA)
+	if err != nil {
+		t.Fatalf("GetClaimableHEZ: %s", err)
+	}
+	if claimable.Cmp(hez(11)) != 0 {
+		t.Fatalf("claimable %s, expected %s", claimable, hez(11))
+	}
+	before := ta.hezBalance(t, addrA)
+	tx, err = bidderA.ClaimHEZ(ctx)
+	mined(t, ta.sim, tx, err)
+	if claimed := new(big.Int).Sub(ta.hezBalance(t, addrA), before); claimed.Cmp(hez(11)) != 0 {
+		t.Fatalf("claimed %s HEZ, expected %s", claimed, hez(11))
+	}
+	if claimable, err = GetClaimableHEZ(ta.hezClient, addrA); err != nil || claimable.Sign() != 0 {
+		t.Fatalf("claimable after claim: %v %v", claimable, err)
+	}
+}
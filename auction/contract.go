@@ -0,0 +1,193 @@
+package auction
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+)
+
+// ErrAuctionContractNotSet is returned when the HermezClient has no Auction contract binding
+var ErrAuctionContractNotSet = errors.New("auction contract is not set")
+
+// GetState reads the configuration of the Auction contract and its current slot
+func GetState(hezClient client.HermezClient) (state State, err error) {
+	if hezClient.AuctionContract == nil {
+		err = fmt.Errorf("[Auction][GetState] Error: %w", ErrAuctionContractNotSet)
+		return
+	}
+	contract := hezClient.AuctionContract
+	currentSlot, err := contract.GetCurrentSlotNumber(nil)
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetState] Error reading current slot - Error: %s", err.Error())
+		return
+	}
+	state.CurrentSlot = currentSlot.Int64()
+	genesisBlock, err := contract.GenesisBlock(nil)
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetState] Error reading genesis block - Error: %s", err.Error())
+		return
+	}
+	state.GenesisBlock = genesisBlock.Int64()
+	blocksPerSlot, err := contract.BLOCKSPERSLOT(nil)
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetState] Error reading blocks per slot - Error: %s", err.Error())
+		return
+	}
+	state.BlocksPerSlot = int64(blocksPerSlot)
+	openAuctionSlots, err := contract.GetOpenAuctionSlots(nil)
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetState] Error reading open auction slots - Error: %s", err.Error())
+		return
+	}
+	state.OpenAuctionSlots = int64(openAuctionSlots)
+	closedAuctionSlots, err := contract.GetClosedAuctionSlots(nil)
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetState] Error reading closed auction slots - Error: %s", err.Error())
+		return
+	}
+	state.ClosedAuctionSlots = int64(closedAuctionSlots)
+	slotDeadline, err := contract.GetSlotDeadline(nil)
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetState] Error reading slot deadline - Error: %s", err.Error())
+		return
+	}
+	state.SlotDeadline = int64(slotDeadline)
+	outbidding, err := contract.GetOutbidding(nil)
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetState] Error reading outbidding - Error: %s", err.Error())
+		return
+	}
+	state.Outbidding = int64(outbidding)
+	for slotSet := 0; slotSet < numSlotSets; slotSet++ {
+		if state.DefaultSlotSetBid[slotSet], err = contract.GetDefaultSlotSetBid(nil, uint8(slotSet)); err != nil {
+			err = fmt.Errorf("[Auction][GetState] Error reading min bid of slot set %d - Error: %s", slotSet, err.Error())
+			return
+		}
+	}
+	if state.BootCoordinator, err = contract.GetBootCoordinator(nil); err != nil {
+		err = fmt.Errorf("[Auction][GetState] Error reading boot coordinator - Error: %s", err.Error())
+		return
+	}
+	if state.BootCoordinatorURL, err = contract.BootCoordinatorURL(nil); err != nil {
+		err = fmt.Errorf("[Auction][GetState] Error reading boot coordinator URL - Error: %s", err.Error())
+		return
+	}
+	if state.DonationAddress, err = contract.GetDonationAddress(nil); err != nil {
+		err = fmt.Errorf("[Auction][GetState] Error reading donation address - Error: %s", err.Error())
+		return
+	}
+	return
+}
+
+// GetMinBidBySlot reads the amount of HEZ a new bid for the slot needs, including the outbidding over the current bid
+func GetMinBidBySlot(hezClient client.HermezClient, slotNum int64) (minBid *big.Int, err error) {
+	if hezClient.AuctionContract == nil {
+		err = fmt.Errorf("[Auction][GetMinBidBySlot] Error: %w", ErrAuctionContractNotSet)
+		return
+	}
+	minBid, err = hezClient.AuctionContract.GetMinBidBySlot(nil, big.NewInt(slotNum))
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetMinBidBySlot] Slot: %d - Error: %s", slotNum, err.Error())
+		return
+	}
+	return
+}
+
+// SlotBlocks returns the first and last Ethereum blocks of the slot
+func (s State) SlotBlocks(slotNum int64) (firstBlock, lastBlock int64) {
+	firstBlock = s.GenesisBlock + slotNum*s.BlocksPerSlot
+	lastBlock = firstBlock + s.BlocksPerSlot - 1
+	return
+}
+
+// SlotNumber returns the slot of the Ethereum block, -1 before the genesis block
+func (s State) SlotNumber(blockNum int64) int64 {
+	if blockNum < s.GenesisBlock || s.BlocksPerSlot == 0 {
+		return -1
+	}
+	return (blockNum - s.GenesisBlock) / s.BlocksPerSlot
+}
+
+// SlotStatus returns the status of the slot relative to the current slot
+func (s State) SlotStatus(slotNum int64) SlotStatus {
+	switch {
+	case slotNum < s.CurrentSlot:
+		return SlotStatusPast
+	case slotNum == s.CurrentSlot:
+		return SlotStatusCurrent
+	case slotNum <= s.CurrentSlot+s.ClosedAuctionSlots:
+		return SlotStatusClosed
+	case slotNum <= s.CurrentSlot+s.ClosedAuctionSlots+s.OpenAuctionSlots:
+		return SlotStatusOpen
+	default:
+		return SlotStatusNotOpen
+	}
+}
+
+// GetSlotSchedule reads from the Auction contract who forges the numSlots slots starting at fromSlot and between
+// which Ethereum blocks. A negative fromSlot starts at the current slot.
+func GetSlotSchedule(hezClient client.HermezClient, fromSlot int64, numSlots int) (schedule []ScheduledSlot, err error) {
+	state, err := GetState(hezClient)
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetSlotSchedule] Error: %w", err)
+		return
+	}
+	if fromSlot < 0 {
+		fromSlot = state.CurrentSlot
+	}
+	forgers := map[ethCommon.Address]ethCommon.Address{}
+	urls := map[ethCommon.Address]string{}
+	for slotNum := fromSlot; slotNum < fromSlot+int64(numSlots); slotNum++ {
+		var scheduled ScheduledSlot
+		if scheduled, err = scheduleSlot(hezClient, state, slotNum, forgers, urls); err != nil {
+			err = fmt.Errorf("[Auction][GetSlotSchedule] Error: %w", err)
+			return
+		}
+		schedule = append(schedule, scheduled)
+	}
+	return
+}
+
+// scheduleSlot reads the bid of the slot and resolves its forger. forgers and urls cache the coordinators already
+// read, by bidder.
+func scheduleSlot(hezClient client.HermezClient, state State, slotNum int64, forgers map[ethCommon.Address]ethCommon.Address,
+	urls map[ethCommon.Address]string) (scheduled ScheduledSlot, err error) {
+	scheduled.SlotNum = slotNum
+	scheduled.FirstBlock, scheduled.LastBlock = state.SlotBlocks(slotNum)
+	scheduled.Status = state.SlotStatus(slotNum)
+
+	slot, err := hezClient.AuctionContract.Slots(nil, big.NewInt(slotNum))
+	if err != nil {
+		err = fmt.Errorf("[Auction][scheduleSlot] Error reading slot %d - Error: %s", slotNum, err.Error())
+		return
+	}
+	scheduled.Bidder = slot.Bidder
+	scheduled.BidAmount = slot.BidAmount
+	// the min bid is frozen when the slot is forged, until then it's the one of its slot set
+	scheduled.MinBid = slot.ClosedMinBid
+	if scheduled.MinBid == nil || scheduled.MinBid.Sign() == 0 {
+		scheduled.MinBid = state.DefaultSlotSetBid[slotNum%numSlotSets]
+	}
+
+	if slot.Bidder == (ethCommon.Address{}) || slot.BidAmount == nil || slot.BidAmount.Cmp(scheduled.MinBid) < 0 {
+		scheduled.Forger = state.BootCoordinator
+		scheduled.CoordinatorURL = state.BootCoordinatorURL
+		scheduled.IsBootCoordinator = true
+		return
+	}
+	if _, ok := forgers[slot.Bidder]; !ok {
+		coordinator, errCoord := hezClient.AuctionContract.Coordinators(nil, slot.Bidder)
+		if errCoord != nil {
+			err = fmt.Errorf("[Auction][scheduleSlot] Error reading coordinator %s - Error: %s", slot.Bidder.Hex(), errCoord.Error())
+			return
+		}
+		forgers[slot.Bidder] = coordinator.Forger
+		urls[slot.Bidder] = coordinator.CoordinatorURL
+	}
+	scheduled.Forger = forgers[slot.Bidder]
+	scheduled.CoordinatorURL = urls[slot.Bidder]
+	return
+}
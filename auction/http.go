@@ -0,0 +1,186 @@
+package auction
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-go-sdk/util"
+)
+
+// auctionPageLimit is the biggest page the hermez node returns
+const auctionPageLimit = 2049
+
+// ErrSlotNotFound is returned when the hermez node doesn't know the slot
+var ErrSlotNotFound = errors.New("slot not found")
+
+// query builds the query string of the filter for the page starting at fromItem, nil for the first page
+func (f SlotFilter) query(fromItem *uint64) string {
+	values := url.Values{}
+	if f.MinSlotNum != nil {
+		values.Set("minSlotNum", strconv.FormatInt(*f.MinSlotNum, 10))
+	}
+	if f.MaxSlotNum != nil {
+		values.Set("maxSlotNum", strconv.FormatInt(*f.MaxSlotNum, 10))
+	}
+	if f.WonByEthereumAddress != nil {
+		values.Set("wonByEthereumAddress", f.WonByEthereumAddress.Hex())
+	}
+	if f.FinishedAuction != nil {
+		values.Set("finishedAuction", strconv.FormatBool(*f.FinishedAuction))
+	}
+	setPagination(values, f.Order, f.Limit, fromItem)
+	return values.Encode()
+}
+
+// query builds the query string of the filter for the page starting at fromItem, nil for the first page
+func (f BidFilter) query(fromItem *uint64) string {
+	values := url.Values{}
+	if f.SlotNum != nil {
+		values.Set("slotNum", strconv.FormatInt(*f.SlotNum, 10))
+	}
+	if f.BidderAddr != nil {
+		values.Set("bidderAddr", f.BidderAddr.Hex())
+	}
+	setPagination(values, f.Order, f.Limit, fromItem)
+	return values.Encode()
+}
+
+func setPagination(values url.Values, order string, limit int, fromItem *uint64) {
+	if len(order) > 0 {
+		values.Set("order", order)
+	}
+	if limit <= 0 || limit > auctionPageLimit {
+		limit = auctionPageLimit
+	}
+	values.Set("limit", strconv.Itoa(limit))
+	if fromItem != nil {
+		values.Set("fromItem", strconv.FormatUint(*fromItem, 10))
+	}
+}
+
+// GetSlot connects to a hermez node and pull an auction slot with its best bid
+func GetSlot(hezClient client.HermezClient, slotNum int64) (slot Slot, err error) {
+	if len(hezClient.BootCoordinatorURL) < 10 {
+		err = fmt.Errorf("[Auction][GetSlot] Boot Coordinator is not set : %s", hezClient.BootCoordinatorURL)
+		return
+	}
+	req, err := hezClient.BootCoordinatorClient.New().Get(fmt.Sprintf("/v1/slots/%d", slotNum)).Request()
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetSlot] Error creating request: %s", err.Error())
+		return
+	}
+	var failureBody interface{}
+	res, err := hezClient.BootCoordinatorClient.Do(req, &slot, &failureBody)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		err = fmt.Errorf("[Auction][GetSlot] Slot: %d - Error: %w", slotNum, ErrSlotNotFound)
+		return
+	}
+	if res != nil && res.StatusCode != http.StatusOK {
+		err = fmt.Errorf("[Auction][GetSlot] Error pulling slot from hermez node: %+v - Error: %d", failureBody, res.StatusCode)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetSlot] Error pulling slot from hermez node: %s - Error: %s", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	return
+}
+
+// GetSlotsPage connects to a hermez node and pull a single page of auction slots. fromItem is the ItemID the page
+// starts at, nil for the first page.
+func GetSlotsPage(hezClient client.HermezClient, filter SlotFilter, fromItem *uint64) (page SlotsAPIResponse, err error) {
+	if len(hezClient.BootCoordinatorURL) < 10 {
+		err = fmt.Errorf("[Auction][GetSlotsPage] Boot Coordinator is not set : %s", hezClient.BootCoordinatorURL)
+		return
+	}
+	req, err := hezClient.BootCoordinatorClient.New().Get("/v1/slots?" + filter.query(fromItem)).Request()
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetSlotsPage] Error creating request: %s", err.Error())
+		return
+	}
+	var failureBody interface{}
+	res, err := hezClient.BootCoordinatorClient.Do(req, &page, &failureBody)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		// the node answers 404 when no slot matches the filter
+		return SlotsAPIResponse{}, nil
+	}
+	if res != nil && res.StatusCode != http.StatusOK {
+		err = fmt.Errorf("[Auction][GetSlotsPage] Error pulling slots from hermez node: %+v - Error: %d", failureBody, res.StatusCode)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetSlotsPage] Error pulling slots from hermez node: %s - Error: %s", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	return
+}
+
+// GetAllSlots connects to a hermez node and pull every auction slot matching the filter, going through all the
+// result pages
+func GetAllSlots(hezClient client.HermezClient, filter SlotFilter) (slots []Slot, err error) {
+	var fromItem *uint64
+	for {
+		var page SlotsAPIResponse
+		page, err = GetSlotsPage(hezClient, filter, fromItem)
+		if err != nil {
+			err = fmt.Errorf("[Auction][GetAllSlots] Error: %w", err)
+			return
+		}
+		slots = append(slots, page.Slots...)
+		if page.PendingItems < 1 || len(page.Slots) < 1 {
+			return
+		}
+		fromItem = util.NextFromItem(page.Slots[len(page.Slots)-1].ItemID, filter.Order)
+	}
+}
+
+// GetBidsPage connects to a hermez node and pull a single page of bids. fromItem is the ItemID the page starts at,
+// nil for the first page.
+func GetBidsPage(hezClient client.HermezClient, filter BidFilter, fromItem *uint64) (page BidsAPIResponse, err error) {
+	if len(hezClient.BootCoordinatorURL) < 10 {
+		err = fmt.Errorf("[Auction][GetBidsPage] Boot Coordinator is not set : %s", hezClient.BootCoordinatorURL)
+		return
+	}
+	req, err := hezClient.BootCoordinatorClient.New().Get("/v1/bids?" + filter.query(fromItem)).Request()
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetBidsPage] Error creating request: %s", err.Error())
+		return
+	}
+	var failureBody interface{}
+	res, err := hezClient.BootCoordinatorClient.Do(req, &page, &failureBody)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		// the node answers 404 when no bid matches the filter
+		return BidsAPIResponse{}, nil
+	}
+	if res != nil && res.StatusCode != http.StatusOK {
+		err = fmt.Errorf("[Auction][GetBidsPage] Error pulling bids from hermez node: %+v - Error: %d", failureBody, res.StatusCode)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("[Auction][GetBidsPage] Error pulling bids from hermez node: %s - Error: %s", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	return
+}
+
+// GetAllBids connects to a hermez node and pull every bid matching the filter, going through all the result pages
+func GetAllBids(hezClient client.HermezClient, filter BidFilter) (bids []Bid, err error) {
+	var fromItem *uint64
+	for {
+		var page BidsAPIResponse
+		page, err = GetBidsPage(hezClient, filter, fromItem)
+		if err != nil {
+			err = fmt.Errorf("[Auction][GetAllBids] Error: %w", err)
+			return
+		}
+		bids = append(bids, page.Bids...)
+		if page.PendingItems < 1 || len(page.Bids) < 1 {
+			return
+		}
+		fromItem = util.NextFromItem(page.Bids[len(page.Bids)-1].ItemID, filter.Order)
+	}
+}
@@ -0,0 +1,115 @@
+package auction
+
+import (
+	"math/big"
+	"time"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-node/common/apitypes"
+)
+
+// numSlotSets is the number of slot sets of the auction, each one with its own default min bid
+const numSlotSets = 6
+
+// SlotsAPIResponse is a page of auction slots
+type SlotsAPIResponse struct {
+	Slots        []Slot `json:"slots"`
+	PendingItems uint64 `json:"pendingItems"`
+}
+
+// Slot is an auction slot as seen by the hermez node. BestBid is nil while nobody bid for it.
+type Slot struct {
+	ItemID      uint64 `json:"itemId"`
+	SlotNum     int64  `json:"slotNum"`
+	FirstBlock  int64  `json:"firstBlock"`
+	LastBlock   int64  `json:"lastBlock"`
+	OpenAuction bool   `json:"openAuction"`
+	BestBid     *Bid   `json:"bestBid"`
+}
+
+// BidsAPIResponse is a page of bids
+type BidsAPIResponse struct {
+	Bids         []Bid  `json:"bids"`
+	PendingItems uint64 `json:"pendingItems"`
+}
+
+// Bid is a bid for a slot. Forger and URL are the ones the bidder registered with setCoordinator.
+type Bid struct {
+	ItemID      uint64             `json:"itemId"`
+	SlotNum     int64              `json:"slotNum"`
+	BidValue    apitypes.BigIntStr `json:"bidValue"`
+	EthBlockNum int64              `json:"ethereumBlockNum"`
+	Bidder      ethCommon.Address  `json:"bidderAddr"`
+	Forger      ethCommon.Address  `json:"forgerAddr"`
+	URL         string             `json:"URL"`
+	Timestamp   time.Time          `json:"timestamp"`
+}
+
+// SlotFilter selects the slots. The hermez node needs MaxSlotNum unless FinishedAuction is true. Order is ASC
+// (default) or DESC, Limit is the page size, up to 2049.
+type SlotFilter struct {
+	MinSlotNum           *int64
+	MaxSlotNum           *int64
+	WonByEthereumAddress *ethCommon.Address
+	FinishedAuction      *bool
+	Order                string
+	Limit                int
+}
+
+// BidFilter selects the bids. The hermez node needs SlotNum or BidderAddr. Order is ASC (default) or DESC, Limit is
+// the page size, up to 2049.
+type BidFilter struct {
+	SlotNum    *int64
+	BidderAddr *ethCommon.Address
+	Order      string
+	Limit      int
+}
+
+// State is the configuration of the Auction contract and its current slot. A slot takes BlocksPerSlot blocks from
+// GenesisBlock on. Bids are accepted after CurrentSlot+ClosedAuctionSlots up to
+// CurrentSlot+ClosedAuctionSlots+OpenAuctionSlots. DefaultSlotSetBid is the min bid of each slot set.
+type State struct {
+	CurrentSlot        int64
+	GenesisBlock       int64
+	BlocksPerSlot      int64
+	OpenAuctionSlots   int64
+	ClosedAuctionSlots int64
+	SlotDeadline       int64
+	Outbidding         int64
+	DefaultSlotSetBid  [numSlotSets]*big.Int
+	BootCoordinator    ethCommon.Address
+	BootCoordinatorURL string
+	DonationAddress    ethCommon.Address
+}
+
+// SlotStatus tells if a slot is being forged, already closed for bids or still in auction
+type SlotStatus string
+
+const (
+	// SlotStatusPast is a slot whose blocks are already mined
+	SlotStatusPast SlotStatus = "past"
+	// SlotStatusCurrent is the slot being forged
+	SlotStatusCurrent SlotStatus = "current"
+	// SlotStatusClosed is a future slot whose auction is closed, its forger is already known
+	SlotStatusClosed SlotStatus = "closed"
+	// SlotStatusOpen is a slot that accepts bids
+	SlotStatusOpen SlotStatus = "open"
+	// SlotStatusNotOpen is a slot whose auction didn't start yet
+	SlotStatusNotOpen SlotStatus = "not-open"
+)
+
+// ScheduledSlot tells who forges a slot and between which Ethereum blocks. Forger and CoordinatorURL are the ones of
+// the winning bidder, or the boot coordinator when the slot has no bid or its bid is below MinBid. While the auction
+// is open the forger may still change.
+type ScheduledSlot struct {
+	SlotNum           int64
+	FirstBlock        int64
+	LastBlock         int64
+	Status            SlotStatus
+	Bidder            ethCommon.Address
+	BidAmount         *big.Int
+	MinBid            *big.Int
+	Forger            ethCommon.Address
+	CoordinatorURL    string
+	IsBootCoordinator bool
+}
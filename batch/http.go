@@ -0,0 +1,173 @@
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-go-sdk/transaction"
+	"github.com/hermeznetwork/hermez-go-sdk/util"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+)
+
+// batchesPageLimit is the biggest page the hermez node returns
+const batchesPageLimit = 2049
+
+// ErrBatchNotFound is returned when the hermez node has no forged batch with the requested number
+var ErrBatchNotFound = errors.New("batch not found")
+
+// query builds the query string of the filter for the page starting at fromItem, nil for the first page
+func (f BatchFilter) query(fromItem *uint64) string {
+	values := url.Values{}
+	if f.ForgerAddr != nil {
+		values.Set("forgerAddr", f.ForgerAddr.Hex())
+	}
+	if f.SlotNum != nil {
+		values.Set("slotNum", strconv.FormatInt(*f.SlotNum, 10))
+	}
+	if f.MinBatchNum != nil {
+		values.Set("minBatchNum", strconv.FormatInt(int64(*f.MinBatchNum), 10))
+	}
+	if f.MaxBatchNum != nil {
+		values.Set("maxBatchNum", strconv.FormatInt(int64(*f.MaxBatchNum), 10))
+	}
+	if len(f.Order) > 0 {
+		values.Set("order", f.Order)
+	}
+	limit := f.Limit
+	if limit <= 0 || limit > batchesPageLimit {
+		limit = batchesPageLimit
+	}
+	values.Set("limit", strconv.Itoa(limit))
+	if fromItem != nil {
+		values.Set("fromItem", strconv.FormatUint(*fromItem, 10))
+	}
+	return values.Encode()
+}
+
+// inBlockRange returns true when the batch was forged inside the Ethereum block range of the filter
+func (f BatchFilter) inBlockRange(batch Batch) bool {
+	if f.MinEthBlockNum > 0 && batch.EthBlockNum < f.MinEthBlockNum {
+		return false
+	}
+	if f.MaxEthBlockNum > 0 && batch.EthBlockNum > f.MaxEthBlockNum {
+		return false
+	}
+	return true
+}
+
+// GetBatch connects to a hermez node and pull a forged batch based on it's number
+func GetBatch(hezClient client.HermezClient, batchNum hezCommon.BatchNum) (batch Batch, err error) {
+	if len(hezClient.BootCoordinatorURL) < 10 {
+		err = fmt.Errorf("[Batch][GetBatch] Boot Coordinator is not set : %s", hezClient.BootCoordinatorURL)
+		return
+	}
+	req, err := hezClient.BootCoordinatorClient.New().Get(fmt.Sprintf("/v1/batches/%d", batchNum)).Request()
+	if err != nil {
+		err = fmt.Errorf("[Batch][GetBatch] Error creating request: %s", err.Error())
+		return
+	}
+	var failureBody interface{}
+	res, err := hezClient.BootCoordinatorClient.Do(req, &batch, &failureBody)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		err = fmt.Errorf("[Batch][GetBatch] Batch: %d - Error: %w", batchNum, ErrBatchNotFound)
+		return
+	}
+	if res != nil && res.StatusCode != http.StatusOK {
+		err = fmt.Errorf("[Batch][GetBatch] Error pulling batch from hermez node: %+v - Error: %d", failureBody, res.StatusCode)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("[Batch][GetBatch] Error pulling batch from hermez node: %s - Error: %s", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	return
+}
+
+// GetBatchesPage connects to a hermez node and pull a single page of forged batches. fromItem is the ItemID the page
+// starts at, nil for the first page. The Ethereum block range of the filter is not applied.
+func GetBatchesPage(hezClient client.HermezClient, filter BatchFilter, fromItem *uint64) (page BatchesAPIResponse, err error) {
+	if len(hezClient.BootCoordinatorURL) < 10 {
+		err = fmt.Errorf("[Batch][GetBatchesPage] Boot Coordinator is not set : %s", hezClient.BootCoordinatorURL)
+		return
+	}
+	req, err := hezClient.BootCoordinatorClient.New().Get("/v1/batches?" + filter.query(fromItem)).Request()
+	if err != nil {
+		err = fmt.Errorf("[Batch][GetBatchesPage] Error creating request: %s", err.Error())
+		return
+	}
+	var failureBody interface{}
+	res, err := hezClient.BootCoordinatorClient.Do(req, &page, &failureBody)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		// the node answers 404 when no batch matches the filter
+		return BatchesAPIResponse{}, nil
+	}
+	if res != nil && res.StatusCode != http.StatusOK {
+		err = fmt.Errorf("[Batch][GetBatchesPage] Error pulling batches from hermez node: %+v - Error: %d", failureBody, res.StatusCode)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("[Batch][GetBatchesPage] Error pulling batches from hermez node: %s - Error: %s", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	return
+}
+
+// ForEachBatch goes through all the pages of forged batches matching the filter and calls fn with each batch, in the
+// filter order. It stops at the first error returned by fn, or when fn returns false.
+func ForEachBatch(hezClient client.HermezClient, filter BatchFilter, fn func(Batch) (bool, error)) (err error) {
+	descending := filter.Order == "DESC"
+	var fromItem *uint64
+	for {
+		var page BatchesAPIResponse
+		page, err = GetBatchesPage(hezClient, filter, fromItem)
+		if err != nil {
+			err = fmt.Errorf("[Batch][ForEachBatch] Error: %w", err)
+			return
+		}
+		for _, batch := range page.Batches {
+			if !filter.inBlockRange(batch) {
+				// the batches are sorted by block, past the range there is nothing else to read
+				if (!descending && filter.MaxEthBlockNum > 0 && batch.EthBlockNum > filter.MaxEthBlockNum) ||
+					(descending && filter.MinEthBlockNum > 0 && batch.EthBlockNum < filter.MinEthBlockNum) {
+					return
+				}
+				continue
+			}
+			var next bool
+			if next, err = fn(batch); err != nil || !next {
+				return
+			}
+		}
+		if page.PendingItems < 1 || len(page.Batches) < 1 {
+			return
+		}
+		fromItem = util.NextFromItem(page.Batches[len(page.Batches)-1].ItemID, filter.Order)
+	}
+}
+
+// GetAllBatches connects to a hermez node and pull every forged batch matching the filter, going through all the
+// result pages
+func GetAllBatches(hezClient client.HermezClient, filter BatchFilter) (batches []Batch, err error) {
+	err = ForEachBatch(hezClient, filter, func(batch Batch) (bool, error) {
+		batches = append(batches, batch)
+		return true, nil
+	})
+	if err != nil {
+		err = fmt.Errorf("[Batch][GetAllBatches] Error: %w", err)
+	}
+	return
+}
+
+// GetBatchTxs connects to a hermez node and pull every L1 and L2 tx forged in the batch from the transactions history,
+// in the order they were processed
+func GetBatchTxs(hezClient client.HermezClient, batchNum hezCommon.BatchNum) (transactions []transaction.HistoryTx, err error) {
+	transactions, err = transaction.GetAllHistoryTxs(hezClient, transaction.HistoryTxFilter{BatchNum: &batchNum})
+	if err != nil {
+		err = fmt.Errorf("[Batch][GetBatchTxs] Batch: %d - Error: %w", batchNum, err)
+	}
+	return
+}
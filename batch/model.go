@@ -0,0 +1,49 @@
+package batch
+
+import (
+	"time"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/common/apitypes"
+)
+
+// BatchesAPIResponse is a page of the forged batches
+type BatchesAPIResponse struct {
+	Batches      []Batch `json:"batches"`
+	PendingItems uint64  `json:"pendingItems"`
+}
+
+// Batch is a forged batch. EthereumTxHash is the L1 tx that forged it and CollectedFees the fees collected by the
+// forger, by token ID.
+type Batch struct {
+	ItemID         uint64                    `json:"itemId"`
+	BatchNum       hezCommon.BatchNum        `json:"batchNum"`
+	EthereumTxHash ethCommon.Hash            `json:"ethereumTxHash"`
+	EthBlockNum    int64                     `json:"ethereumBlockNum"`
+	EthBlockHash   ethCommon.Hash            `json:"ethereumBlockHash"`
+	Timestamp      time.Time                 `json:"timestamp"`
+	ForgerAddr     ethCommon.Address         `json:"forgerAddr"`
+	CollectedFees  apitypes.CollectedFeesAPI `json:"collectedFees"`
+	TotalFeesUSD   *float64                  `json:"historicTotalCollectedFeesUSD"`
+	StateRoot      apitypes.BigIntStr        `json:"stateRoot"`
+	NumAccounts    int                       `json:"numAccounts"`
+	ExitRoot       apitypes.BigIntStr        `json:"exitRoot"`
+	ForgeL1TxsNum  *int64                    `json:"forgeL1TransactionsNum"`
+	SlotNum        int64                     `json:"slotNum"`
+	ForgedTxs      int                       `json:"forgedTransactions"`
+}
+
+// BatchFilter selects the forged batches. Empty fields don't filter. MinEthBlockNum and MaxEthBlockNum aren't
+// supported by the hermez node, they are applied while going through the pages. Order is ASC (default) or DESC,
+// Limit is the page size, up to 2049.
+type BatchFilter struct {
+	ForgerAddr     *ethCommon.Address
+	SlotNum        *int64
+	MinBatchNum    *hezCommon.BatchNum
+	MaxBatchNum    *hezCommon.BatchNum
+	MinEthBlockNum int64
+	MaxEthBlockNum int64
+	Order          string
+	Limit          int
+}
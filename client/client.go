@@ -46,6 +46,7 @@ func NewHermezClient(nodeURL string, auctionContractAddressHex string, ethereumC
 	}
 
 	hezClient.AuctionContract = auctionContract
+	hezClient.AuctionContractAddress = auctionContractAddress
 	bootCoordURL, err := hezClient.AuctionContract.BootCoordinatorURL(nil)
 	if err != nil {
 		log.Printf("Error during boot coordinator url query: %s - auctionContractAddressHex: %s\n", err.Error(), auctionContractAddressHex)
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/dghubble/sling"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	HermezAuctionProtocol "github.com/hermeznetwork/hermez-node/eth/contracts/auction"
 )
@@ -12,12 +13,16 @@ import (
 type HermezClient struct {
 	EthClient                *ethclient.Client
 	AuctionContract          *HermezAuctionProtocol.Auction
+	AuctionContractAddress   common.Address
 	HttpClient               http.Client
 	BootCoordinatorURL       string
 	BootCoordinatorClient    *sling.Sling
 	CurrentCoordinatorURL    string
 	CurrentCoordinatorClient *sling.Sling
 	EthereumChainID          int
+	// SubmitCheck, when set, is called before submitting txs or account creation authorizations to the current
+	// coordinator. An error stops the submission, e.g. node.RequireSyncedNode refuses to submit to a node that is behind.
+	SubmitCheck func(hezClient HermezClient) error
 }
 
 // SetCurrentCoordinator updates coordinator definitions based on current coordinator URL
@@ -26,3 +31,11 @@ func (hezClient *HermezClient) SetCurrentCoordinator(URL string) {
 	httpClient := NewHttpClient()
 	hezClient.CurrentCoordinatorClient = sling.New().Base(hezClient.CurrentCoordinatorURL).Client(&httpClient)
 }
+
+// CheckSubmit runs the SubmitCheck of the client, nil when it has none
+func (hezClient HermezClient) CheckSubmit() error {
+	if hezClient.SubmitCheck == nil {
+		return nil
+	}
+	return hezClient.SubmitCheck(hezClient)
+}
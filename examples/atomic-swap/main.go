@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"os"
+
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-go-sdk/node"
+	"github.com/hermeznetwork/hermez-go-sdk/transaction"
+)
+
+const (
+	ethereumNodeURL           = ""
+	auctionContractAddressHex = "0x1D5c3Dd2003118743D596D7DB7EA07de6C90fB20"
+	proposerHezBjjAddress     = ""
+	counterpartyHezBjjAddress = ""
+	swapFilePath              = "./hermez-atomic-swap.json"
+)
+
+// Both parties agree on the swap terms beforehand, each one checks them before signing. Token symbols aren't unique,
+// the token IDs are the ones listed by token.GetTokens.
+var swap = transaction.AtomicSwap{
+	Proposer: transaction.AtomicSwapParty{
+		HezBjjAddress: proposerHezBjjAddress,
+		TokenID:       1,
+		TokenSymbol:   "HEZ",
+		Amount:        big.NewInt(1000000000000000000),
+	},
+	Counterparty: transaction.AtomicSwapParty{
+		HezBjjAddress: counterpartyHezBjjAddress,
+		TokenID:       2,
+		TokenSymbol:   "USDT",
+		Amount:        big.NewInt(5000000),
+	},
+	FeeRangeSelectedID: 126,
+}
+
+// Usage:
+//
+//	proposer:     go run . propose
+//	each party:   PVT_KEY=... go run . sign
+//	either party: go run . submit
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatalln("Usage: atomic-swap propose|sign|submit")
+	}
+	hezClient, err := newHermezClient()
+	if err != nil {
+		log.Printf("Error during Hermez client initialization: %s\n", err.Error())
+		return
+	}
+	switch os.Args[1] {
+	case "propose":
+		propose(hezClient)
+	case "sign":
+		sign(hezClient)
+	case "submit":
+		submit(hezClient)
+	default:
+		log.Fatalln("Usage: atomic-swap propose|sign|submit")
+	}
+}
+
+func newHermezClient() (hezClient client.HermezClient, err error) {
+	log.Println("Starting Hermez Client...")
+	hezClient, err = client.NewHermezClient(ethereumNodeURL, auctionContractAddressHex, 5)
+	if err != nil {
+		return
+	}
+	bootCoordNodeState, err := node.GetBootCoordinatorNodeInfo(hezClient)
+	if err != nil {
+		return
+	}
+	hezClient.SetCurrentCoordinator(bootCoordNodeState.Network.NextForgers[0].Coordinator.URL)
+	return
+}
+
+func propose(hezClient client.HermezClient) {
+	psag, err := transaction.ProposeAtomicSwap(hezClient, swap)
+	if err != nil {
+		log.Printf("Error proposing swap: %s\n", err.Error())
+		return
+	}
+	if err = save(psag); err != nil {
+		return
+	}
+	log.Printf("Swap proposal saved at %s, send it to the counterparty:\n%s", swapFilePath, psag)
+}
+
+func sign(hezClient client.HermezClient) {
+	sourceAccPvtKey := os.Getenv("PVT_KEY")
+	if len(sourceAccPvtKey) < 64 {
+		log.Fatalln("Invalid Private key.")
+	}
+	bjjWallet, _, err := account.CreateBjjWalletFromHexPvtKey(sourceAccPvtKey)
+	if err != nil {
+		log.Printf("Error Create a Babyjubjub Wallet from Hexdecimal Private Key. Error: %s\n", err.Error())
+		return
+	}
+	defer bjjWallet.Destroy()
+
+	psag, err := load()
+	if err != nil {
+		return
+	}
+	log.Printf("Swap to sign:\n%s", psag)
+	if err = transaction.SignAtomicSwap(hezClient, &psag, swap, bjjWallet); err != nil {
+		log.Printf("Error signing swap: %s\n", err.Error())
+		return
+	}
+	if err = save(psag); err != nil {
+		return
+	}
+	log.Printf("Swap signed and saved at %s - missing signers: %v\n", swapFilePath, psag.MissingSigners())
+}
+
+func submit(hezClient client.HermezClient) {
+	psag, err := load()
+	if err != nil {
+		return
+	}
+	serverResponse, err := transaction.SubmitPartiallySignedAtomicGroup(hezClient, psag)
+	if err != nil {
+		log.Printf("Error submitting swap: %s\n", err.Error())
+		return
+	}
+	log.Printf("Atomic group %s submitted: %s\n", psag.AtomicGroup.ID.String(), serverResponse)
+}
+
+func load() (psag transaction.PartiallySignedAtomicGroup, err error) {
+	data, err := ioutil.ReadFile(swapFilePath)
+	if err != nil {
+		log.Printf("Error reading %s: %s\n", swapFilePath, err.Error())
+		return
+	}
+	psag, err = transaction.UnmarshalPartiallySignedAtomicGroup(data)
+	if err != nil {
+		log.Printf("Error loading swap: %s\n", err.Error())
+	}
+	return
+}
+
+func save(psag transaction.PartiallySignedAtomicGroup) (err error) {
+	data, err := json.MarshalIndent(psag, "", "  ")
+	if err != nil {
+		log.Printf("Error marshaling swap: %s\n", err.Error())
+		return
+	}
+	if err = ioutil.WriteFile(swapFilePath, data, 0600); err != nil {
+		log.Printf("Error writing %s: %s\n", swapFilePath, err.Error())
+	}
+	return
+}
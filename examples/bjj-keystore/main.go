@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+)
+
+const (
+	keyStoreDir = "./hermez-keystore"
+)
+
+func main() {
+	password := os.Getenv("KEYSTORE_PASSWORD")
+	if len(password) < 1 {
+		log.Fatalln("KEYSTORE_PASSWORD is not set.")
+	}
+
+	log.Println("Opening keystore...")
+	ks, err := account.NewBJJKeyStore(keyStoreDir, account.StandardScryptN, account.StandardScryptP)
+	if err != nil {
+		log.Printf("Error opening keystore: %s\n", err.Error())
+		return
+	}
+
+	entries, err := ks.List()
+	if err != nil {
+		log.Printf("Error listing keystore wallets: %s\n", err.Error())
+		return
+	}
+
+	if len(entries) < 1 {
+		log.Println("Keystore is empty. Getting pvt key from env...")
+		sourceAccPvtKey := os.Getenv("PVT_KEY")
+		if len(sourceAccPvtKey) < 64 {
+			log.Fatalln("Invalid Private key.")
+		}
+
+		bjjWallet, _, err := account.CreateBjjWalletFromHexPvtKey(sourceAccPvtKey)
+		if err != nil {
+			log.Printf("Error Create a Babyjubjub Wallet from Hexdecimal Private Key. Error: %s\n", err.Error())
+			return
+		}
+
+		path, err := ks.Store(bjjWallet, password)
+		if err != nil {
+			log.Printf("Error storing wallet: %s\n", err.Error())
+			return
+		}
+		log.Println("Wallet stored at: ", path)
+
+		entries, err = ks.List()
+		if err != nil {
+			log.Printf("Error listing keystore wallets: %s\n", err.Error())
+			return
+		}
+	}
+
+	for _, entry := range entries {
+		bjjWallet, err := ks.Load(entry.Address.Hex(), password)
+		if errors.Is(err, account.ErrKeyStoreInvalidPassword) {
+			log.Printf("Wrong password to wallet %s\n", entry.HezEthAddress)
+			continue
+		}
+		if err != nil {
+			log.Printf("Error loading wallet %s: %s\n", entry.HezEthAddress, err.Error())
+			return
+		}
+		log.Printf("Wallet loaded. Hez Ethereum address: %s - Hez BJJ address: %s\n", bjjWallet.HezEthAddress, bjjWallet.HezBjjAddress)
+	}
+}
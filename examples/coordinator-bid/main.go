@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"os"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/auction"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+)
+
+// Reads ETH_NODE_URL and ETH_NETWORK like client.NewHermezClientFromEnv, the bidder Ethereum private key from
+// PVT_KEY and the forger address and URL from FORGER_ADDRESS and COORDINATOR_URL
+func main() {
+	log.Println("Starting Hermez Client...")
+	hezClient, err := client.NewHermezClientFromEnv()
+	if err != nil {
+		log.Printf("Error during Hermez client initialization: %s\n", err.Error())
+		return
+	}
+	wallet, _, err := account.CreateBjjWalletFromHexPvtKey(os.Getenv("PVT_KEY"))
+	if err != nil {
+		log.Printf("Error loading the bidder wallet: %s\n", err.Error())
+		return
+	}
+	bidder, err := auction.NewBidder(hezClient, nil, wallet)
+	if err != nil {
+		log.Printf("Error creating the bidder: %s\n", err.Error())
+		return
+	}
+	ctx := context.Background()
+
+	forger := ethCommon.HexToAddress(os.Getenv("FORGER_ADDRESS"))
+	coordinatorURL := os.Getenv("COORDINATOR_URL")
+	registeredForger, registeredURL, registered, err := auction.GetCoordinator(hezClient, wallet.EthAddress())
+	if err != nil {
+		log.Printf("Error reading the coordinator: %s\n", err.Error())
+		return
+	}
+	if !registered || registeredForger != forger || registeredURL != coordinatorURL {
+		tx, err := bidder.SetCoordinator(ctx, forger, coordinatorURL)
+		if err != nil {
+			log.Printf("Error registering the coordinator: %s\n", err.Error())
+			return
+		}
+		log.Printf("Coordinator registration sent: %s\n", tx.Hash().Hex())
+	}
+
+	state, err := auction.GetState(hezClient)
+	if err != nil {
+		log.Printf("Error reading the auction contract: %s\n", err.Error())
+		return
+	}
+	slot := state.CurrentSlot + state.ClosedAuctionSlots + 1
+	minBid, err := auction.GetMinBidBySlot(hezClient, slot)
+	if err != nil {
+		log.Printf("Error reading the min bid: %s\n", err.Error())
+		return
+	}
+	tx, err := bidder.Bid(ctx, slot, minBid, auction.BidFundingPermit)
+	if err != nil {
+		log.Printf("Error bidding: %s\n", err.Error())
+		return
+	}
+	log.Printf("Bid of %s HEZ for slot %d sent: %s\n", minBid.String(), slot, tx.Hash().Hex())
+
+	outbid, err := auction.GetOutbidSlots(hezClient, wallet.EthAddress(), state.CurrentSlot)
+	if err != nil {
+		log.Printf("Error reading the outbid slots: %s\n", err.Error())
+		return
+	}
+	for _, status := range outbid {
+		log.Printf("Outbid in slot %d by %s with %s HEZ\n", status.SlotNum, status.BestBidder.Hex(), status.BestBid.String())
+	}
+	claimable, err := auction.GetClaimableHEZ(hezClient, wallet.EthAddress())
+	if err != nil {
+		log.Printf("Error reading the claimable HEZ: %s\n", err.Error())
+		return
+	}
+	if claimable.Cmp(big.NewInt(0)) > 0 {
+		log.Printf("%s HEZ can be claimed with ClaimHEZ\n", claimable.String())
+	}
+}
@@ -1,18 +1,12 @@
 package main
 
 import (
-	"fmt"
-	"io"
-	"io/ioutil"
 	"log"
-	"net/http"
 
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/hermeznetwork/hermez-go-sdk/account"
 	"github.com/hermeznetwork/hermez-go-sdk/client"
 	sdkcommon "github.com/hermeznetwork/hermez-go-sdk/common"
 	"github.com/hermeznetwork/hermez-go-sdk/node"
-	"github.com/hermeznetwork/hermez-go-sdk/util"
 	"github.com/jeffprestes/goethereumhelper"
 )
 
@@ -76,65 +70,17 @@ func main() {
 		return
 	}
 
-	accountCreation := account.AccountCreation{}
-	accountCreation.EthereumAddress = bjjWallet.HezEthAddress
-	accountCreation.HezBjjAddress = bjjWallet.HezBjjAddress
-	accountCreation.Signature = bjjWallet.AccountCreationAuthSignature
-
-	log.Printf("\naccount creation: %+v\n", accountCreation)
-
-	apiTxBody, err := util.MarshallBody(accountCreation)
+	log.Println("Submitting account creation authorization...")
+	err = account.CreateAccountCreationAuth(hezClient, bjjWallet)
 	if err != nil {
-		err = fmt.Errorf("[] Error marshaling HTTP request tx: %+v - Error: %s\n", accountCreation, err.Error())
-		log.Println("Error marshaling HTTP request: ", err.Error())
+		log.Println("Error posting account creation authorization: ", err.Error())
 		return
 	}
 
-	var URL string
-	URL = hezClient.CurrentCoordinatorURL + "/v1/account-creation-authorization"
-	request, err := http.NewRequest("POST", URL, apiTxBody)
-	if err != nil {
-		err = fmt.Errorf("[] Error creating HTTP request. URL: %s - request: %+v - Error: %s\n", URL, apiTxBody, err.Error())
-		log.Println("Error creating HTTP request: ", err.Error())
-		return
-	}
-	request.Header.Set("Content-Type", "application/json")
-	request.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:88.0) Gecko/20100101 Firefox/88.0")
-	request.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
-	request.Header.Set("Accept-Encoding", "gzip, deflate, br")
-
-	if debug {
-		log.Printf("Submitting...\n%+v\n%+v\n", accountCreation, request)
-	}
-
-	response, err := hezClient.HttpClient.Do(request)
+	created, err := account.HasAccountCreationAuth(hezClient, randomAccount.Hex())
 	if err != nil {
-		err = fmt.Errorf("[] Error submitting HTTP request tx. URL: %s - request: %+v - Error: %s\n", URL, apiTxBody, err.Error())
-		log.Println("Error submitting HTTP: ", err.Error())
+		log.Println("Error pulling account creation authorization: ", err.Error())
 		return
 	}
-	defer response.Body.Close()
-
-	if response.StatusCode < 200 || response.StatusCode > 299 {
-		tempBuf, errResp := io.ReadAll(response.Body)
-		if errResp != nil {
-			err = fmt.Errorf("[] Error unmarshaling tx: %+v - Error: %s\n", accountCreation, errResp.Error())
-			return
-		}
-		strJSONRequest := string(tempBuf)
-		err = fmt.Errorf("[] Error posting account: %+v\nStatusCode:%d \nStatus: %s\nReturned Message: %s\nURL: %s \nRequest: %+v \nResponse: %+v\n",
-			accountCreation, response.StatusCode, response.Status, strJSONRequest, URL, request, response)
-		log.Println("Error request: ", err.Error())
-		return
-	}
-
-	b, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		err = fmt.Errorf("[] Error reading HTTP return from Coordinator. URL: %s - request: %+v - Error: %s\n", URL, apiTxBody, err.Error())
-		log.Println("Error ReadAll: ", err.Error())
-		return
-	}
-
-	serverResponse := fmt.Sprintln("Transaction ID submmited: ", accountCreation.HezBjjAddress, "Message returned in Hex: %s\n", common.Bytes2Hex(b))
-	log.Println("Success!", serverResponse)
+	log.Println("Success! Account creation authorization stored: ", created)
 }
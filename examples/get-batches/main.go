@@ -0,0 +1,65 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-go-sdk/batch"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	sdkcommon "github.com/hermeznetwork/hermez-go-sdk/common"
+)
+
+const (
+	ethereumNodeURL = "https://mainnet.infura.io/v3/"
+	network         = "mainnet"
+)
+
+// Usage: go run . <forger address>
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatalln("Usage: get-batches <forger address>")
+	}
+	log.Println("Starting Hermez Client...")
+	networkDefinition, err := sdkcommon.GetNetworkDefinition(network)
+	if err != nil {
+		log.Printf("Error getting hermez definition at %s . Error: %s\n", network, err.Error())
+		return
+	}
+	hezClient, err := client.NewHermezClient(ethereumNodeURL, networkDefinition.AuctionContractAddress.Hex(), networkDefinition.ChainID)
+	if err != nil {
+		log.Printf("Error during Hermez client initialization: %s\n", err.Error())
+		return
+	}
+
+	log.Println("Getting the last batches of the forger ...")
+	forger := ethCommon.HexToAddress(os.Args[1])
+	filter := batch.BatchFilter{
+		ForgerAddr: &forger,
+		Order:      "DESC",
+		Limit:      10,
+	}
+	page, err := batch.GetBatchesPage(hezClient, filter, nil)
+	if err != nil {
+		log.Printf("Error obtaining batches. URL: %s - Error: %s\n", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	for _, forged := range page.Batches {
+		log.Printf("Batch %d - slot: %d - block: %d - L1 tx: %s - txs: %d - fees: %v\n", forged.BatchNum, forged.SlotNum,
+			forged.EthBlockNum, forged.EthereumTxHash.Hex(), forged.ForgedTxs, forged.CollectedFees)
+	}
+	if len(page.Batches) == 0 {
+		return
+	}
+
+	last := page.Batches[0]
+	txs, err := batch.GetBatchTxs(hezClient, last.BatchNum)
+	if err != nil {
+		log.Printf("Error obtaining the txs of batch %d - Error: %s\n", last.BatchNum, err.Error())
+		return
+	}
+	log.Printf("Transactions forged in batch %d:\n", last.BatchNum)
+	for _, tx := range txs {
+		log.Printf("%s %s %s %s %s\n", tx.TxID.String(), tx.L1orL2, tx.Type, tx.Amount, tx.Token.Symbol)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log"
+
+	"github.com/hermeznetwork/hermez-go-sdk/auction"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	sdkcommon "github.com/hermeznetwork/hermez-go-sdk/common"
+)
+
+const (
+	ethereumNodeURL = "https://mainnet.infura.io/v3/"
+	network         = "mainnet"
+	slotsToSchedule = 10
+)
+
+func main() {
+	log.Println("Starting Hermez Client...")
+	networkDefinition, err := sdkcommon.GetNetworkDefinition(network)
+	if err != nil {
+		log.Printf("Error getting hermez definition at %s . Error: %s\n", network, err.Error())
+		return
+	}
+	hezClient, err := client.NewHermezClient(ethereumNodeURL, networkDefinition.AuctionContractAddress.Hex(), networkDefinition.ChainID)
+	if err != nil {
+		log.Printf("Error during Hermez client initialization: %s\n", err.Error())
+		return
+	}
+
+	state, err := auction.GetState(hezClient)
+	if err != nil {
+		log.Printf("Error reading the auction contract: %s\n", err.Error())
+		return
+	}
+	log.Printf("Current slot: %d - open auction slots: %d - closed auction slots: %d - boot coordinator: %s %s - donation address: %s\n",
+		state.CurrentSlot, state.OpenAuctionSlots, state.ClosedAuctionSlots, state.BootCoordinator.Hex(), state.BootCoordinatorURL,
+		state.DonationAddress.Hex())
+	for slotSet, minBid := range state.DefaultSlotSetBid {
+		log.Printf("Min bid of slot set %d: %s\n", slotSet, minBid.String())
+	}
+
+	log.Println("Slot schedule:")
+	schedule, err := auction.GetSlotSchedule(hezClient, -1, slotsToSchedule)
+	if err != nil {
+		log.Printf("Error reading the slot schedule: %s\n", err.Error())
+		return
+	}
+	for _, slot := range schedule {
+		log.Printf("Slot %d [%s] blocks %d-%d - forger: %s %s - boot coordinator: %t\n", slot.SlotNum, slot.Status,
+			slot.FirstBlock, slot.LastBlock, slot.Forger.Hex(), slot.CoordinatorURL, slot.IsBootCoordinator)
+	}
+
+	open := state.CurrentSlot + state.ClosedAuctionSlots + 1
+	bids, err := auction.GetAllBids(hezClient, auction.BidFilter{SlotNum: &open})
+	if err != nil {
+		log.Printf("Error obtaining bids. URL: %s - Error: %s\n", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	log.Printf("Bids for slot %d:\n", open)
+	for _, bid := range bids {
+		log.Printf("%s bid %s - forger: %s %s\n", bid.Bidder.Hex(), bid.BidValue, bid.Forger.Hex(), bid.URL)
+	}
+}
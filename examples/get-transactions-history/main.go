@@ -0,0 +1,60 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	sdkcommon "github.com/hermeznetwork/hermez-go-sdk/common"
+	"github.com/hermeznetwork/hermez-go-sdk/transaction"
+)
+
+const (
+	ethereumNodeURL = "https://mainnet.infura.io/v3/"
+	network         = "mainnet"
+)
+
+// Usage: go run . <hez Ethereum address>
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatalln("Usage: get-transactions-history <hez Ethereum address>")
+	}
+	log.Println("Starting Hermez Client...")
+	networkDefinition, err := sdkcommon.GetNetworkDefinition(network)
+	if err != nil {
+		log.Printf("Error getting hermez definition at %s . Error: %s\n", network, err.Error())
+		return
+	}
+	hezClient, err := client.NewHermezClient(ethereumNodeURL, networkDefinition.AuctionContractAddress.Hex(), networkDefinition.ChainID)
+	if err != nil {
+		log.Printf("Error during Hermez client initialization: %s\n", err.Error())
+		return
+	}
+
+	log.Println("Getting the last forged transactions of the address ...")
+	filter := transaction.HistoryTxFilter{
+		HezEthereumAddress: os.Args[1],
+		Order:              "DESC",
+		Limit:              50,
+	}
+	count := 0
+	err = transaction.ForEachHistoryTx(hezClient, filter, func(tx transaction.HistoryTx) (bool, error) {
+		count++
+		if tx.IsL1() {
+			log.Printf("%s %s L1 %s %s - batch: %d\n", tx.Timestamp, tx.Type, tx.Amount, tx.Token.Symbol, tx.ForgedBatchNum())
+			return count < 200, nil
+		}
+		from := "-"
+		if tx.FromIdx != nil {
+			from = string(*tx.FromIdx)
+		}
+		log.Printf("%s %s L2 %s %s - batch: %d - from: %s to: %s\n", tx.Timestamp, tx.Type, tx.Amount, tx.Token.Symbol,
+			tx.ForgedBatchNum(), from, tx.ToIdx)
+		return count < 200, nil
+	})
+	if err != nil {
+		log.Printf("Error obtaining transactions history. URL: %s - Error: %s\n", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	log.Println("Request finished. Transactions read: ", count)
+}
@@ -49,5 +49,8 @@ func main() {
 	log.Println("Request finished. Total transactions in the pool: ", len(apiResponseTxs.Transactions))
 	for _, tx := range apiResponseTxs.Transactions {
 		log.Printf("%+v\n", tx)
+		if err := tx.PoolError(); err != nil {
+			log.Printf("  %s - retryable: %t\n", err.Error(), transaction.IsRetryablePoolTxError(err))
+		}
 	}
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	sdkcommon "github.com/hermeznetwork/hermez-go-sdk/common"
+	"github.com/hermeznetwork/hermez-go-sdk/node"
+)
+
+const (
+	ethereumNodeURL = "https://mainnet.infura.io/v3/"
+	network         = "mainnet"
+)
+
+func main() {
+	log.Println("Starting Hermez Client...")
+	networkDefinition, err := sdkcommon.GetNetworkDefinition(network)
+	if err != nil {
+		log.Printf("Error getting hermez definition at %s . Error: %s\n", network, err.Error())
+		return
+	}
+	hezClient, err := client.NewHermezClient(ethereumNodeURL, networkDefinition.AuctionContractAddress.Hex(), networkDefinition.ChainID)
+	if err != nil {
+		log.Printf("Error during Hermez client initialization: %s\n", err.Error())
+		return
+	}
+
+	log.Println("Getting the registered coordinators ...")
+	coordinators, err := node.GetAllCoordinators(hezClient, node.CoordinatorFilter{})
+	if err != nil {
+		log.Printf("Error obtaining coordinators. URL: %s - Error: %s\n", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	log.Printf("Probing %d coordinators ...\n", len(coordinators))
+	// compare the coordinators with the head of our own Ethereum node, not with the head they report
+	probes, err := node.ProbeCoordinatorsWithEthHead(context.Background(), hezClient, coordinators, node.ProbeOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		log.Printf("Error probing coordinators: %s\n", err.Error())
+		return
+	}
+	for _, probe := range probes {
+		if !probe.Reachable {
+			log.Printf("%s (forger %s, registered at block %d) unreachable: %s\n", probe.Coordinator.URL,
+				probe.Coordinator.Forger.Hex(), probe.Coordinator.EthBlockNum, probe.Err.Error())
+			continue
+		}
+		log.Printf("%s (forger %s, registered at block %d) - latency: %s - last batch: %d - blocks behind: %d - synced: %t\n",
+			probe.Coordinator.URL, probe.Coordinator.Forger.Hex(), probe.Coordinator.EthBlockNum, probe.Latency,
+			probe.LastBatchNum, probe.BlocksBehind, probe.Synced)
+	}
+}
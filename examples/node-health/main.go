@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	sdkcommon "github.com/hermeznetwork/hermez-go-sdk/common"
+	"github.com/hermeznetwork/hermez-go-sdk/node"
+)
+
+const (
+	ethereumNodeURL = "https://mainnet.infura.io/v3/"
+	network         = "mainnet"
+)
+
+func main() {
+	log.Println("Starting Hermez Client...")
+	networkDefinition, err := sdkcommon.GetNetworkDefinition(network)
+	if err != nil {
+		log.Printf("Error getting hermez definition at %s . Error: %s\n", network, err.Error())
+		return
+	}
+	hezClient, err := client.NewHermezClient(ethereumNodeURL, networkDefinition.AuctionContractAddress.Hex(), networkDefinition.ChainID)
+	if err != nil {
+		log.Printf("Error during Hermez client initialization: %s\n", err.Error())
+		return
+	}
+
+	ctx := context.Background()
+	thresholds := node.HealthThresholds{MaxBlocksBehind: 10}
+	log.Println("Checking the boot coordinator ...")
+	printHealth(node.CheckBootCoordinatorHealth(ctx, hezClient, thresholds))
+	log.Println("Checking the current coordinator ...")
+	printHealth(node.CheckCurrentCoordinatorHealth(ctx, hezClient, thresholds))
+
+	// From now on every tx submitted with hezClient is refused while the current coordinator lags behind
+	hezClient.SubmitCheck = node.RequireSyncedNode(thresholds)
+	if err = hezClient.CheckSubmit(); errors.Is(err, node.ErrNodeLagging) {
+		log.Printf("Submitting txs to %s would be refused: %s\n", hezClient.CurrentCoordinatorURL, err.Error())
+		return
+	} else if err != nil {
+		log.Printf("Error checking %s: %s\n", hezClient.CurrentCoordinatorURL, err.Error())
+		return
+	}
+	log.Printf("Txs can be submitted to %s\n", hezClient.CurrentCoordinatorURL)
+}
+
+func printHealth(health node.NodeHealth, err error) {
+	if err != nil {
+		log.Printf("Error checking node health: %s\n", err.Error())
+		return
+	}
+	log.Printf("%s - Ethereum head: %d - synced block: %d (%d behind) - Rollup batch: %d - node batch: %d (%d behind) - healthy: %t\n",
+		health.URL, health.EthHeadBlock, health.LastSyncBlock, health.BlocksBehind, health.ContractLastBatch,
+		health.NodeLastBatch, health.BatchesBehind, health.Healthy)
+	for _, issue := range health.Issues {
+		log.Printf("  %s\n", issue)
+	}
+}
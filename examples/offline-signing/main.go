@@ -0,0 +1,137 @@
+package main
+
+import (
+	"log"
+	"math/big"
+	"os"
+
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-go-sdk/node"
+	"github.com/hermeznetwork/hermez-go-sdk/transaction"
+)
+
+const (
+	ethereumNodeURL           = ""
+	auctionContractAddressHex = "0x1D5c3Dd2003118743D596D7DB7EA07de6C90fB20"
+	senderHezBjjAddress       = ""
+	offlineFilePath           = "./hermez-offline-txs.json"
+)
+
+// Usage:
+//
+//	online machine:  go run . prepare
+//	offline machine: PVT_KEY=... go run . sign
+//	online machine:  go run . broadcast
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatalln("Usage: offline-signing prepare|sign|broadcast")
+	}
+	switch os.Args[1] {
+	case "prepare":
+		prepare()
+	case "sign":
+		sign()
+	case "broadcast":
+		broadcast()
+	default:
+		log.Fatalln("Usage: offline-signing prepare|sign|broadcast")
+	}
+}
+
+func newHermezClient() (hezClient client.HermezClient, err error) {
+	log.Println("Starting Hermez Client...")
+	hezClient, err = client.NewHermezClient(ethereumNodeURL, auctionContractAddressHex, 5)
+	if err != nil {
+		return
+	}
+	bootCoordNodeState, err := node.GetBootCoordinatorNodeInfo(hezClient)
+	if err != nil {
+		return
+	}
+	hezClient.SetCurrentCoordinator(bootCoordNodeState.Network.NextForgers[0].Coordinator.URL)
+	return
+}
+
+func prepare() {
+	hezClient, err := newHermezClient()
+	if err != nil {
+		log.Printf("Error during Hermez client initialization: %s\n", err.Error())
+		return
+	}
+	sender, err := account.NewWatchOnlyBJJSigner(senderHezBjjAddress)
+	if err != nil {
+		log.Printf("Error loading sender address: %s\n", err.Error())
+		return
+	}
+
+	offlineTx, err := transaction.PrepareL2Transfer(hezClient,
+		sender,
+		"0x263C3Ab7E4832eDF623fBdD66ACee71c028Ff591",
+		"HEZ",
+		big.NewInt(983000000000000000),
+		126)
+	if err != nil {
+		log.Printf("Error preparing transfer: %s\n", err.Error())
+		return
+	}
+
+	offlineFile := transaction.NewOfflineFile(hezClient.EthereumChainID, []transaction.OfflineTx{offlineTx}, nil)
+	if err = transaction.SaveOfflineFile(offlineFilePath, offlineFile); err != nil {
+		log.Printf("Error saving offline file: %s\n", err.Error())
+		return
+	}
+	log.Printf("Unsigned txs saved at %s:\n%s", offlineFilePath, offlineFile)
+}
+
+func sign() {
+	sourceAccPvtKey := os.Getenv("PVT_KEY")
+	if len(sourceAccPvtKey) < 64 {
+		log.Fatalln("Invalid Private key.")
+	}
+	bjjWallet, _, err := account.CreateBjjWalletFromHexPvtKey(sourceAccPvtKey)
+	if err != nil {
+		log.Printf("Error Create a Babyjubjub Wallet from Hexdecimal Private Key. Error: %s\n", err.Error())
+		return
+	}
+	defer bjjWallet.Destroy()
+
+	offlineFile, err := transaction.LoadOfflineFile(offlineFilePath)
+	if err != nil {
+		log.Printf("Error loading offline file: %s\n", err.Error())
+		return
+	}
+	log.Printf("Txs to sign:\n%s", offlineFile)
+
+	signed, err := transaction.SignOfflineFile(&offlineFile, bjjWallet)
+	if err != nil {
+		log.Printf("Error signing offline file: %s\n", err.Error())
+		return
+	}
+	if err = transaction.SaveOfflineFile(offlineFilePath, offlineFile); err != nil {
+		log.Printf("Error saving offline file: %s\n", err.Error())
+		return
+	}
+	log.Printf("%d txs signed and saved at %s\n", signed, offlineFilePath)
+}
+
+func broadcast() {
+	hezClient, err := newHermezClient()
+	if err != nil {
+		log.Printf("Error during Hermez client initialization: %s\n", err.Error())
+		return
+	}
+	offlineFile, err := transaction.LoadOfflineFile(offlineFilePath)
+	if err != nil {
+		log.Printf("Error loading offline file: %s\n", err.Error())
+		return
+	}
+	results, err := transaction.BroadcastOfflineFile(hezClient, offlineFile)
+	for _, result := range results {
+		log.Printf("Transaction submitted: %s\n", result.ServerResponse)
+	}
+	if err != nil {
+		log.Printf("Error broadcasting offline file: %s\n", err.Error())
+		return
+	}
+}
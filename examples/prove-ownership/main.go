@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+)
+
+const (
+	verifierDomain = "exchange.example.com"
+)
+
+func main() {
+	sourceAccPvtKey := os.Getenv("PVT_KEY")
+	if len(sourceAccPvtKey) < 64 {
+		log.Fatalln("Invalid Private key.")
+	}
+
+	bjjWallet, _, err := account.CreateBjjWalletFromHexPvtKey(sourceAccPvtKey)
+	if err != nil {
+		log.Printf("Error Create a Babyjubjub Wallet from Hexdecimal Private Key. Error: %s\n", err.Error())
+		return
+	}
+
+	// Verifier side: issue a challenge for the BJJ address the user wants to whitelist
+	challenge, err := account.NewOwnershipChallenge(verifierDomain, bjjWallet.HezBjjAddress, 5*time.Minute)
+	if err != nil {
+		log.Printf("Error creating challenge: %s\n", err.Error())
+		return
+	}
+	log.Printf("Challenge to sign:\n%s\n", challenge.Message())
+
+	// User side: a BJJ address needs the BJJ signature, a hez:0x address the Ethereum one
+	proof, err := account.SignOwnershipChallenge(challenge, bjjWallet, bjjWallet)
+	if err != nil {
+		log.Printf("Error signing challenge: %s\n", err.Error())
+		return
+	}
+	proofJSON, _ := json.MarshalIndent(proof, "", "  ")
+	log.Printf("Proof:\n%s\n", string(proofJSON))
+
+	// Verifier side: check the proof against the challenge it issued
+	if err = account.VerifyOwnershipProof(challenge, proof); err != nil {
+		log.Printf("Invalid proof: %s\n", err.Error())
+		return
+	}
+	log.Println("Ownership proven for: ", challenge.HezAddress)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"math/big"
+
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-go-sdk/node"
+	"github.com/hermeznetwork/hermez-go-sdk/transaction"
+)
+
+const (
+	ethereumNodeURL           = ""
+	sourceAccPvtKey1          = ""
+	sourceAccPvtKey2          = ""
+	sourceAccPvtKey3          = ""
+	auctionContractAddressHex = "0x1D5c3Dd2003118743D596D7DB7EA07de6C90fB20"
+)
+
+func main() {
+	log.Println("Starting Hermez Client...")
+	hezClient, err := client.NewHermezClient(ethereumNodeURL, auctionContractAddressHex, 5)
+	if err != nil {
+		log.Printf("Error during Hermez client initialization: %s\n", err.Error())
+		return
+	}
+
+	bootCoordNodeState, err := node.GetBootCoordinatorNodeInfo(hezClient)
+	if err != nil {
+		log.Printf("Error obtaining boot coordinator info. URL: %s - Error: %s\n", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	hezClient.SetCurrentCoordinator(bootCoordNodeState.Network.NextForgers[0].Coordinator.URL)
+
+	var wallets []account.BJJWallet
+	for _, pvtKey := range []string{sourceAccPvtKey1, sourceAccPvtKey2, sourceAccPvtKey3} {
+		bjjWallet, _, err := account.CreateBjjWalletFromHexPvtKey(pvtKey)
+		if err != nil {
+			log.Printf("Error Create a Babyjubjub Wallet from Hexdecimal Private Key - Error: %s\n", err.Error())
+			return
+		}
+		wallets = append(wallets, bjjWallet)
+	}
+
+	// The txs are created without requirement, the builder sets the RqOffsets from the links below
+	items := []transaction.AtomicTxItem{
+		{
+			SenderBjjWallet:       wallets[0],
+			ReceiverAddress:       wallets[1].EthAccount.Address.Hex(),
+			TokenSymbolToTransfer: "HEZ",
+			Amount:                big.NewInt(100000000000000000),
+			FeeRangeSelectedID:    126,
+		},
+		{
+			SenderBjjWallet:       wallets[1],
+			ReceiverAddress:       wallets[2].EthAccount.Address.Hex(),
+			TokenSymbolToTransfer: "HEZ",
+			Amount:                big.NewInt(100000000000000000),
+			FeeRangeSelectedID:    126,
+		},
+		{
+			SenderBjjWallet:       wallets[2],
+			ReceiverAddress:       wallets[0].EthAccount.Address.Hex(),
+			TokenSymbolToTransfer: "HEZ",
+			Amount:                big.NewInt(100000000000000000),
+			FeeRangeSelectedID:    126,
+		},
+	}
+	fullTxs, err := transaction.CreateFullTxs(hezClient, items)
+	if err != nil {
+		log.Printf("Error generating PoolL2Txs: %s\n", err.Error())
+		return
+	}
+
+	builder := transaction.NewAtomicGroupBuilder()
+	txA := builder.Add(fullTxs[0])
+	txB := builder.Add(fullTxs[1])
+	txC := builder.Add(fullTxs[2])
+	builder.Require(txA, txB).Require(txB, txC).Require(txC, txA)
+	atomicGroup, err := builder.Build()
+	var groupErr *transaction.AtomicGroupError
+	if errors.As(err, &groupErr) {
+		log.Printf("Invalid atomic group, tx at position %d: %s\n", groupErr.Position, groupErr.Err.Error())
+		return
+	} else if err != nil {
+		log.Printf("Error building atomic group: %s\n", err.Error())
+		return
+	}
+	log.Printf("Atomic group ID: %s\n", atomicGroup.ID.String())
+
+	for i := range atomicGroup.Txs {
+		txHash, err := atomicGroup.Txs[i].HashToSign(uint16(hezClient.EthereumChainID))
+		if err != nil {
+			log.Printf("Error generating tx hash: %s\n", err.Error())
+			return
+		}
+		signature, err := wallets[i].SignPoseidon(txHash)
+		if err != nil {
+			log.Printf("Error signing tx: %s\n", err.Error())
+			return
+		}
+		atomicGroup.Txs[i].Signature = signature.Compress()
+	}
+
+	server, err := transaction.SendAtomicTxsGroup(hezClient, atomicGroup)
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	log.Println(server)
+}
@@ -119,7 +119,11 @@ func main() {
 			log.Printf(err.Error())
 			return
 		}
-		signature := txs[currentAtomicTxId].SenderBjjWallet.PrivateKey.SignPoseidon(txHash)
+		signature, err := txs[currentAtomicTxId].SenderBjjWallet.SignPoseidon(txHash)
+		if err != nil {
+			log.Printf("[AtomicTransfer] Error signing tx. Error: %s\n", err.Error())
+			return
+		}
 		atomicGroup.Txs[currentAtomicTxId].Signature = signature.Compress()
 	}
 
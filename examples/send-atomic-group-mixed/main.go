@@ -0,0 +1,86 @@
+package main
+
+import (
+	"log"
+	"math/big"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-go-sdk/node"
+	"github.com/hermeznetwork/hermez-go-sdk/transaction"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+)
+
+const (
+	ethereumNodeURL           = ""
+	sourceAccPvtKey1          = ""
+	sourceAccPvtKey2          = ""
+	sourceAccPvtKey3          = ""
+	auctionContractAddressHex = "0x1D5c3Dd2003118743D596D7DB7EA07de6C90fB20"
+)
+
+func main() {
+	log.Println("Starting Hermez Client...")
+	hezClient, err := client.NewHermezClient(ethereumNodeURL, auctionContractAddressHex, 5)
+	if err != nil {
+		log.Printf("Error during Hermez client initialization: %s\n", err.Error())
+		return
+	}
+	bootCoordNodeState, err := node.GetBootCoordinatorNodeInfo(hezClient)
+	if err != nil {
+		log.Printf("Error obtaining boot coordinator info. URL: %s - Error: %s\n", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	hezClient.SetCurrentCoordinator(bootCoordNodeState.Network.NextForgers[0].Coordinator.URL)
+
+	var wallets []account.BJJWallet
+	for _, pvtKey := range []string{sourceAccPvtKey1, sourceAccPvtKey2, sourceAccPvtKey3} {
+		bjjWallet, _, err := account.CreateBjjWalletFromHexPvtKey(pvtKey)
+		if err != nil {
+			log.Printf("Error Create a Babyjubjub Wallet from Hexdecimal Private Key - Error: %s\n", err.Error())
+			return
+		}
+		wallets = append(wallets, bjjWallet)
+	}
+
+	txs := []transaction.AtomicTxItem{
+		// wallet 1 exits HEZ to L1 ...
+		{
+			SenderBjjWallet:       wallets[0],
+			TokenSymbolToTransfer: "HEZ",
+			Amount:                big.NewInt(100000000000000000),
+			FeeRangeSelectedID:    126,
+			RqOffSet:              1, //+1
+			TxType:                hezCommon.TxTypeExit,
+		},
+		// ... only if wallet 2 pays an Ethereum address without L2 account yet ...
+		{
+			SenderBjjWallet:       wallets[1],
+			TokenSymbolToTransfer: "HEZ",
+			Amount:                big.NewInt(100000000000000000),
+			FeeRangeSelectedID:    126,
+			RqOffSet:              1, //+1
+			TxType:                hezCommon.TxTypeTransferToEthAddr,
+			Recipient:             transaction.AtomicRecipient{EthAddr: ethCommon.HexToAddress("0x263C3Ab7E4832eDF623fBdD66ACee71c028Ff591")},
+		},
+		// ... and wallet 3 pays the BJJ key of wallet 1
+		{
+			SenderBjjWallet:       wallets[2],
+			TokenSymbolToTransfer: "HEZ",
+			Amount:                big.NewInt(100000000000000000),
+			FeeRangeSelectedID:    126,
+			RqOffSet:              6, //-2
+			TxType:                hezCommon.TxTypeTransferToBJJ,
+			Recipient:             transaction.AtomicRecipient{BJJ: wallets[0].BJJPublicKey()},
+		},
+	}
+
+	server, atomicGroupID, err := transaction.AtomicTransfer(hezClient, txs)
+	log.Printf("Atomic group ID: %s", atomicGroupID)
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	log.Println(server)
+}
@@ -88,8 +88,6 @@ func main() {
 
 	if debug {
 		log.Println("[L2Transfer] Parameters")
-		log.Printf("hezClient: %+v\n", hezClient)
-		log.Printf("senderBjjWallet: %+v", bjjWallet)
 		log.Println("receiverAddress: ", receiverAddress)
 		log.Println("tokenSymbolToTransfer: ", tokenSymbolToTransfer)
 		log.Println("amount: ", amount.String())
@@ -109,7 +107,6 @@ func main() {
 		log.Printf("\n\nSender Account details from Coordinator: %+v\n\n", senderAccDetails)
 		log.Println("BJJ Address in server: ", senderAccDetails.Accounts[0].BJJAddress)
 		log.Println("BJJ Address local: ", bjjWallet.HezBjjAddress)
-		log.Printf("Wallet details %+v\n", bjjWallet)
 	}
 
 	receiverAccDetails, err := account.GetAccountInfo(hezClient, receiverAddress)
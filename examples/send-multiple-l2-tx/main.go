@@ -3,7 +3,6 @@ package main
 import (
 	"encoding/json"
 	"log"
-	"math/big"
 
 	"github.com/hermeznetwork/hermez-go-sdk/account"
 	"github.com/hermeznetwork/hermez-go-sdk/client"
@@ -122,29 +121,18 @@ func main() {
 		return
 	}
 
-	for _, txMd := range txsMd {
-
-		amount, ok := big.NewInt(0).SetString(txMd.Amount, 10)
-		if !ok {
-			log.Printf("Error parsing tx receiver metadata amount: %s - Error: %s\n", txMd.Amount, err.Error())
-			return
-		}
-
-		apiTx, err := transaction.NewSignedAPITxToEthAddr(networkDefinition.ChainID, bjjWallet, idx, txMd.ToEthAddr, amount, hezcommon.FeeSelector(txMd.FeeSelector), hezToken, nonce)
-		if err != nil {
-			log.Printf("Error creating tx to Eth Address: %s - Error: %s\n", txMd.ToEthAddr, err.Error())
-			return
-		}
+	results, err := transaction.L2TransfersToEthAddr(hezClient, bjjWallet, idx, txsMd, hezToken, nonce, transaction.RecipientCheckSkip)
+	if err != nil {
+		log.Printf("Error executing txs to Eth Addresses - Error: %s\n", err.Error())
+		return
+	}
 
-		apiTx, response, err := transaction.ExecuteL2Transaction(hezClient, apiTx)
-		if err != nil {
-			log.Printf("Error executing tx to Eth Address: %s - Error: %s\n", txMd.ToEthAddr, err.Error())
-			return
+	for _, result := range results {
+		if result.Skipped {
+			log.Printf("Recipient %s skipped. It has no account nor account creation authorization\n", result.Receiver.ToEthAddr)
+			continue
 		}
-
-		log.Println("Transaction ID: ", apiTx.TxID.String())
-		log.Printf("Transaction submitted: %s\n", response)
-
-		nonce++
+		log.Println("Transaction ID: ", result.APITx.TxID.String())
+		log.Printf("Transaction submitted: %s\n", result.ServerResponse)
 	}
 }
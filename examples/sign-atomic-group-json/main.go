@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-go-sdk/node"
+	"github.com/hermeznetwork/hermez-go-sdk/transaction"
+)
+
+const (
+	ethereumNodeURL           = ""
+	auctionContractAddressHex = "0x1D5c3Dd2003118743D596D7DB7EA07de6C90fB20"
+)
+
+// The legs reference the wallets by alias, see transaction.AtomicGroupJSON for the schema.
+// Usage: ALICE_PVT_KEY=... BOB_PVT_KEY=... go run . atomic-group.json
+const exampleGroupJSON = `{
+  "legs": [
+    { "id": "alice-pays", "from": "alice", "to": "bob", "token": "HEZ", "amount": "100000000000000000", "fee": 126, "requires": "bob-pays" },
+    { "id": "bob-pays", "from": "bob", "to": "alice", "token": "HEZ", "amount": "200000000000000000", "fee": 126, "requires": "alice-pays" }
+  ]
+}`
+
+func main() {
+	groupJSON := []byte(exampleGroupJSON)
+	if len(os.Args) > 1 {
+		var err error
+		if groupJSON, err = ioutil.ReadFile(os.Args[1]); err != nil {
+			log.Printf("Error reading %s: %s\n", os.Args[1], err.Error())
+			return
+		}
+	}
+
+	signers := make(map[string]account.BJJSigner)
+	for alias, envVar := range map[string]string{"alice": "ALICE_PVT_KEY", "bob": "BOB_PVT_KEY"} {
+		bjjWallet, _, err := account.CreateBjjWalletFromHexPvtKey(os.Getenv(envVar))
+		if err != nil {
+			log.Printf("Error Create a Babyjubjub Wallet from Hexdecimal Private Key. Alias: %s - Error: %s\n", alias, err.Error())
+			return
+		}
+		defer bjjWallet.Destroy()
+		signers[alias] = bjjWallet
+	}
+
+	log.Println("Starting Hermez Client...")
+	hezClient, err := client.NewHermezClient(ethereumNodeURL, auctionContractAddressHex, 5)
+	if err != nil {
+		log.Printf("Error during Hermez client initialization: %s\n", err.Error())
+		return
+	}
+	bootCoordNodeState, err := node.GetBootCoordinatorNodeInfo(hezClient)
+	if err != nil {
+		log.Printf("Error obtaining boot coordinator info. URL: %s - Error: %s\n", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	hezClient.SetCurrentCoordinator(bootCoordNodeState.Network.NextForgers[0].Coordinator.URL)
+
+	server, atomicGroupID, legs, err := transaction.AtomicTransferFromJSON(hezClient, groupJSON, signers)
+	for _, leg := range legs {
+		log.Printf("Leg %s from %s: %s\n", leg.ID, leg.From, leg.TxID.String())
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	log.Printf("Atomic group %s submitted: %s\n", atomicGroupID.String(), server)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-go-sdk/node"
+	"github.com/hermeznetwork/hermez-go-sdk/transaction"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+)
+
+const (
+	ethereumNodeURL           = ""
+	auctionContractAddressHex = "0x1D5c3Dd2003118743D596D7DB7EA07de6C90fB20"
+)
+
+// Usage: go run . <atomic group ID>
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatalln("Usage: track-atomic-group <atomic group ID>")
+	}
+	atomicGroupID, err := hezCommon.NewAtomicGroupIDFromString(os.Args[1])
+	if err != nil {
+		log.Fatalf("Invalid atomic group ID: %s\n", err.Error())
+	}
+
+	log.Println("Starting Hermez Client...")
+	hezClient, err := client.NewHermezClient(ethereumNodeURL, auctionContractAddressHex, 5)
+	if err != nil {
+		log.Printf("Error during Hermez client initialization: %s\n", err.Error())
+		return
+	}
+	bootCoordNodeState, err := node.GetBootCoordinatorNodeInfo(hezClient)
+	if err != nil {
+		log.Printf("Error obtaining boot coordinator info. URL: %s - Error: %s\n", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	// the group must be tracked on the coordinator it was sent to
+	hezClient.SetCurrentCoordinator(bootCoordNodeState.Network.NextForgers[0].Coordinator.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	status, err := transaction.WaitForAtomicGroup(ctx, hezClient, atomicGroupID, transaction.DefaultAtomicGroupPollInterval)
+	for _, leg := range status.Legs {
+		log.Printf("Leg %s: %s - batch: %d - error code: %d %s %s\n", leg.TxID.String(), leg.State, leg.BatchNum, leg.ErrorCode, leg.ErrorType, leg.Info)
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	log.Printf("Atomic group %s is %s - batch: %d\n", status.ID.String(), status.State, status.BatchNum)
+}
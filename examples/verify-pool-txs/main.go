@@ -0,0 +1,37 @@
+package main
+
+import (
+	"log"
+
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-go-sdk/transaction"
+)
+
+const (
+	ethereumNodeURL           = ""
+	auctionContractAddressHex = "0x1D5c3Dd2003118743D596D7DB7EA07de6C90fB20"
+)
+
+func main() {
+	log.Println("Starting Hermez Client...")
+	hezClient, err := client.NewHermezClient(ethereumNodeURL, auctionContractAddressHex, 5)
+	if err != nil {
+		log.Printf("Error during Hermez client initialization: %s\n", err.Error())
+		return
+	}
+
+	log.Println("Auditing boot coordinator transactions pool...")
+	verifications, err := transaction.AuditTransactionsPool(hezClient)
+	if err != nil {
+		log.Printf("Error auditing transactions pool: %s\n", err.Error())
+		return
+	}
+	for _, verification := range verifications {
+		if verification.OK() {
+			continue
+		}
+		log.Printf("Transaction %s - valid TxID: %t (computed %s) - valid signature: %t\n",
+			verification.TxID.String(), verification.ValidTxID, verification.ComputedTxID.String(), verification.ValidSignature)
+	}
+	log.Printf("%d transactions verified\n", len(verifications))
+}
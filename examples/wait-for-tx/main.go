@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-go-sdk/node"
+	"github.com/hermeznetwork/hermez-go-sdk/transaction"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+)
+
+const (
+	ethereumNodeURL           = ""
+	auctionContractAddressHex = "0x1D5c3Dd2003118743D596D7DB7EA07de6C90fB20"
+)
+
+// Usage: go run . <tx ID>
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatalln("Usage: wait-for-tx <tx ID>")
+	}
+	txID, err := hezCommon.NewTxIDFromString(os.Args[1])
+	if err != nil {
+		log.Fatalf("Invalid tx ID: %s\n", err.Error())
+	}
+
+	log.Println("Starting Hermez Client...")
+	hezClient, err := client.NewHermezClient(ethereumNodeURL, auctionContractAddressHex, 5)
+	if err != nil {
+		log.Printf("Error during Hermez client initialization: %s\n", err.Error())
+		return
+	}
+	bootCoordNodeState, err := node.GetBootCoordinatorNodeInfo(hezClient)
+	if err != nil {
+		log.Printf("Error obtaining boot coordinator info. URL: %s - Error: %s\n", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	// the pool queried is the one of the coordinator the tx was sent to
+	hezClient.SetCurrentCoordinator(bootCoordNodeState.Network.NextForgers[0].Coordinator.URL)
+
+	opts := transaction.WaitOptions{
+		PollInterval: 15 * time.Second,
+		Timeout:      30 * time.Minute,
+	}
+	outcome, err := transaction.WaitForTx(context.Background(), hezClient, txID, opts)
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	switch outcome.State {
+	case transaction.TxOutcomeForged:
+		log.Printf("Tx %s forged in batch %d at %s\n", txID.String(), outcome.BatchNum, outcome.Timestamp)
+	case transaction.TxOutcomeInvalid:
+		err = outcome.Err()
+		if errors.Is(err, transaction.ErrPoolTxNonceTooLow) {
+			log.Printf("Tx %s rejected, its nonce was already used: %s\n", txID.String(), err.Error())
+			return
+		}
+		log.Printf("Tx %s rejected: %s\n", txID.String(), err.Error())
+	default:
+		log.Printf("Tx %s is %s\n", txID.String(), outcome.State)
+	}
+}
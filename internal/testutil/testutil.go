@@ -0,0 +1,20 @@
+// Package testutil holds the fixtures shared by the tests of the SDK packages
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	// PvtKeyA is the Ethereum private key of the first test wallet, never fund it outside tests
+	PvtKeyA = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	// PvtKeyB is the Ethereum private key of the second test wallet, never fund it outside tests
+	PvtKeyB = "8da4ef21b864d2cc526dbdb2a120bd2874c36c9d0a1fb7f8c63d7f7a8b41de8f"
+)
+
+// WriteJSON writes v as the JSON body of a stand-in hermez node response
+func WriteJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,19 @@
+// Package testwallet builds the BJJWallets of the testutil keys. It is apart from testutil so the tests of the account
+// package, which can't import account back, can still use the shared keys.
+package testwallet
+
+import (
+	"testing"
+
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+)
+
+// New creates the BJJWallet of the Ethereum private key, e.g. testutil.PvtKeyA, and fails the test on error
+func New(t testing.TB, hexPvtKey string) account.BJJWallet {
+	t.Helper()
+	wallet, _, err := account.CreateBjjWalletFromHexPvtKey(hexPvtKey)
+	if err != nil {
+		t.Fatalf("creating wallet: %s", err)
+	}
+	return wallet
+}
@@ -0,0 +1,236 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-go-sdk/util"
+)
+
+const (
+	// coordinatorsPageLimit is the biggest page the hermez node returns
+	coordinatorsPageLimit = 2049
+	// DefaultProbeTimeout is how long ProbeCoordinator waits for the /v1/state of a coordinator
+	DefaultProbeTimeout = 10 * time.Second
+	// DefaultMaxBlocksBehind is how many Ethereum blocks a node can be behind its last known block and still be synced
+	DefaultMaxBlocksBehind = 5
+)
+
+// ErrCoordinatorNotFound is returned when no coordinator is registered with the forger address
+var ErrCoordinatorNotFound = errors.New("coordinator not found")
+
+// CoordinatorsAPIResponse is a page of registered coordinators
+type CoordinatorsAPIResponse struct {
+	Coordinators []Coordinator `json:"coordinators"`
+	PendingItems uint64        `json:"pendingItems"`
+}
+
+// Coordinator is a coordinator registered in the Auction contract. EthBlockNum is the block of its registration.
+type Coordinator struct {
+	ItemID      uint64            `json:"itemId"`
+	Bidder      ethCommon.Address `json:"bidderAddr"`
+	Forger      ethCommon.Address `json:"forgerAddr"`
+	EthBlockNum int64             `json:"ethereumBlock"`
+	URL         string            `json:"URL"`
+}
+
+// CoordinatorFilter selects the registered coordinators. Empty fields don't filter. Order is ASC (default) or DESC,
+// Limit is the page size, up to 2049.
+type CoordinatorFilter struct {
+	BidderAddr *ethCommon.Address
+	ForgerAddr *ethCommon.Address
+	Order      string
+	Limit      int
+}
+
+// ProbeOptions configures ProbeCoordinator. Zero values take the defaults. EthHeadBlock is the Ethereum head the
+// synced block of the coordinators is compared with, e.g. read with hezClient.EthClient.BlockNumber. When zero the
+// last Ethereum block reported by each coordinator is used instead.
+type ProbeOptions struct {
+	Timeout         time.Duration
+	MaxBlocksBehind int64
+	EthHeadBlock    int64
+}
+
+// CoordinatorProbe is the result of querying the /v1/state of a coordinator. Err is set when it isn't reachable.
+// BlocksBehind is how far its synchronizer is from the Ethereum head. SelfReported is true when that head is the
+// LastEthBlock of the coordinator itself: a coordinator whose Ethereum node is stuck still looks synced then.
+type CoordinatorProbe struct {
+	Coordinator   Coordinator
+	Reachable     bool
+	Latency       time.Duration
+	LastEthBlock  int64
+	LastSyncBlock int64
+	BlocksBehind  int64
+	SelfReported  bool
+	LastBatchNum  int64
+	Synced        bool
+	Err           error
+}
+
+// query builds the query string of the filter for the page starting at fromItem, nil for the first page
+func (f CoordinatorFilter) query(fromItem *uint64) string {
+	values := url.Values{}
+	if f.BidderAddr != nil {
+		values.Set("bidderAddr", f.BidderAddr.Hex())
+	}
+	if f.ForgerAddr != nil {
+		values.Set("forgerAddr", f.ForgerAddr.Hex())
+	}
+	if len(f.Order) > 0 {
+		values.Set("order", f.Order)
+	}
+	limit := f.Limit
+	if limit <= 0 || limit > coordinatorsPageLimit {
+		limit = coordinatorsPageLimit
+	}
+	values.Set("limit", strconv.Itoa(limit))
+	if fromItem != nil {
+		values.Set("fromItem", strconv.FormatUint(*fromItem, 10))
+	}
+	return values.Encode()
+}
+
+// GetCoordinatorsPage connects to a hermez node and pull a single page of registered coordinators. fromItem is the
+// ItemID the page starts at, nil for the first page.
+func GetCoordinatorsPage(hezClient client.HermezClient, filter CoordinatorFilter, fromItem *uint64) (page CoordinatorsAPIResponse, err error) {
+	if len(hezClient.BootCoordinatorURL) < 10 {
+		err = fmt.Errorf("[Node][GetCoordinatorsPage] Boot Coordinator is not set : %s", hezClient.BootCoordinatorURL)
+		return
+	}
+	req, err := hezClient.BootCoordinatorClient.New().Get("/v1/coordinators?" + filter.query(fromItem)).Request()
+	if err != nil {
+		err = fmt.Errorf("[Node][GetCoordinatorsPage] Error creating request: %s", err.Error())
+		return
+	}
+	var failureBody interface{}
+	res, err := hezClient.BootCoordinatorClient.Do(req, &page, &failureBody)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		// the node answers 404 when no coordinator matches the filter
+		return CoordinatorsAPIResponse{}, nil
+	}
+	if res != nil && res.StatusCode != http.StatusOK {
+		err = fmt.Errorf("[Node][GetCoordinatorsPage] Error pulling coordinators from hermez node: %+v - Error: %d", failureBody, res.StatusCode)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("[Node][GetCoordinatorsPage] Error pulling coordinators from hermez node: %s - Error: %s", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	return
+}
+
+// GetAllCoordinators connects to a hermez node and pull every registered coordinator matching the filter, going
+// through all the result pages
+func GetAllCoordinators(hezClient client.HermezClient, filter CoordinatorFilter) (coordinators []Coordinator, err error) {
+	var fromItem *uint64
+	for {
+		var page CoordinatorsAPIResponse
+		page, err = GetCoordinatorsPage(hezClient, filter, fromItem)
+		if err != nil {
+			err = fmt.Errorf("[Node][GetAllCoordinators] Error: %w", err)
+			return
+		}
+		coordinators = append(coordinators, page.Coordinators...)
+		if page.PendingItems < 1 || len(page.Coordinators) < 1 {
+			return
+		}
+		fromItem = util.NextFromItem(page.Coordinators[len(page.Coordinators)-1].ItemID, filter.Order)
+	}
+}
+
+// GetCoordinatorByForger connects to a hermez node and pull the coordinator registered with the forger address. When
+// it was registered several times the last registration is returned.
+func GetCoordinatorByForger(hezClient client.HermezClient, forger ethCommon.Address) (coordinator Coordinator, err error) {
+	page, err := GetCoordinatorsPage(hezClient, CoordinatorFilter{ForgerAddr: &forger, Order: "DESC", Limit: 1}, nil)
+	if err != nil {
+		err = fmt.Errorf("[Node][GetCoordinatorByForger] Error: %w", err)
+		return
+	}
+	if len(page.Coordinators) == 0 {
+		err = fmt.Errorf("[Node][GetCoordinatorByForger] Forger: %s - Error: %w", forger.Hex(), ErrCoordinatorNotFound)
+		return
+	}
+	return page.Coordinators[0], nil
+}
+
+// ProbeCoordinator queries the /v1/state of the coordinator and reports whether it answers, how long it takes and
+// whether its synchronizer is up to date. Without opts.EthHeadBlock the sync status is self-reported by the
+// coordinator, see ProbeCoordinatorsWithEthHead. The query gives up when ctx is done. Probe failures are reported in
+// CoordinatorProbe.Err.
+func ProbeCoordinator(ctx context.Context, coordinator Coordinator, opts ProbeOptions) (probe CoordinatorProbe) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultProbeTimeout
+	}
+	if opts.MaxBlocksBehind <= 0 {
+		opts.MaxBlocksBehind = DefaultMaxBlocksBehind
+	}
+	probe.Coordinator = coordinator
+	if len(coordinator.URL) < 10 {
+		probe.Err = fmt.Errorf("[Node][ProbeCoordinator] Invalid coordinator URL: %s", coordinator.URL)
+		return
+	}
+	start := time.Now()
+	nodeState, err := getNodeState(ctx, coordinator.URL, opts.Timeout)
+	probe.Latency = time.Since(start)
+	if err != nil {
+		probe.Err = fmt.Errorf("[Node][ProbeCoordinator] Error: %w", err)
+		return
+	}
+	probe.Reachable = true
+	probe.LastEthBlock = nodeState.Network.LastEthBlock
+	probe.LastSyncBlock = nodeState.Network.LastSyncBlock
+	ethHeadBlock := opts.EthHeadBlock
+	if ethHeadBlock <= 0 {
+		ethHeadBlock = probe.LastEthBlock
+		probe.SelfReported = true
+	}
+	probe.BlocksBehind = ethHeadBlock - probe.LastSyncBlock
+	if nodeState.Network.LastBatch != nil {
+		probe.LastBatchNum = int64(nodeState.Network.LastBatch.BatchNum)
+	}
+	probe.Synced = probe.BlocksBehind <= opts.MaxBlocksBehind
+	return
+}
+
+// ProbeCoordinators probes every coordinator concurrently, all of them give up when ctx is done. The probes keep the
+// order of the coordinators.
+func ProbeCoordinators(ctx context.Context, coordinators []Coordinator, opts ProbeOptions) (probes []CoordinatorProbe) {
+	probes = make([]CoordinatorProbe, len(coordinators))
+	var wg sync.WaitGroup
+	for i := range coordinators {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			probes[i] = ProbeCoordinator(ctx, coordinators[i], opts)
+		}(i)
+	}
+	wg.Wait()
+	return
+}
+
+// ProbeCoordinatorsWithEthHead reads the Ethereum head through hezClient.EthClient and probes every coordinator
+// against it, so a coordinator can't report itself as synced. ctx bounds the head query and the probes. It fails with
+// ErrEthClientNotSet without Ethereum client.
+func ProbeCoordinatorsWithEthHead(ctx context.Context, hezClient client.HermezClient, coordinators []Coordinator,
+	opts ProbeOptions) (probes []CoordinatorProbe, err error) {
+	if hezClient.EthClient == nil {
+		err = fmt.Errorf("[Node][ProbeCoordinatorsWithEthHead] Error: %w", ErrEthClientNotSet)
+		return
+	}
+	headBlock, err := hezClient.EthClient.BlockNumber(ctx)
+	if err != nil {
+		err = fmt.Errorf("[Node][ProbeCoordinatorsWithEthHead] Error reading Ethereum head - Error: %s", err.Error())
+		return
+	}
+	opts.EthHeadBlock = int64(headBlock)
+	return ProbeCoordinators(ctx, coordinators, opts), nil
+}
@@ -0,0 +1,87 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-go-sdk/internal/testutil"
+)
+
+// newTestCoordinator serves a /v1/state whose synchronizer reached lastSyncBlock of the lastEthBlock it knows
+func newTestCoordinator(t *testing.T, lastEthBlock, lastSyncBlock int64) Coordinator {
+	nodeState := testNodeState(lastSyncBlock, 7)
+	nodeState.Network.LastEthBlock = lastEthBlock
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/state" {
+			http.NotFound(w, r)
+			return
+		}
+		testutil.WriteJSON(w, nodeState)
+	}))
+	t.Cleanup(srv.Close)
+	return Coordinator{URL: srv.URL}
+}
+
+func TestProbeCoordinator(t *testing.T) {
+	// the coordinator believes it is synced, but its Ethereum node stopped 100 blocks ago
+	coordinator := newTestCoordinator(t, 1000, 1000)
+
+	probe := ProbeCoordinator(context.Background(), coordinator, ProbeOptions{})
+	if probe.Err != nil || !probe.Reachable || probe.LastBatchNum != 7 {
+		t.Fatalf("probe: %+v", probe)
+	}
+	if !probe.SelfReported || !probe.Synced || probe.BlocksBehind != 0 {
+		t.Errorf("self-reported probe: %+v", probe)
+	}
+
+	probe = ProbeCoordinator(context.Background(), coordinator, ProbeOptions{EthHeadBlock: 1100})
+	if probe.SelfReported || probe.Synced || probe.BlocksBehind != 100 {
+		t.Errorf("probe against the Ethereum head: %+v", probe)
+	}
+	probe = ProbeCoordinator(context.Background(), coordinator, ProbeOptions{EthHeadBlock: 1000 + DefaultMaxBlocksBehind})
+	if !probe.Synced {
+		t.Errorf("probe within the default threshold: %+v", probe)
+	}
+}
+
+func TestProbeCoordinatorUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	probe := ProbeCoordinator(context.Background(), Coordinator{URL: srv.URL}, ProbeOptions{})
+	if probe.Reachable || probe.Synced || probe.Err == nil {
+		t.Errorf("probe of a coordinator without state: %+v", probe)
+	}
+}
+
+func TestProbeCoordinatorsWithEthHeadWithoutClient(t *testing.T) {
+	coordinators := []Coordinator{newTestCoordinator(t, 1000, 1000)}
+	if _, err := ProbeCoordinatorsWithEthHead(context.Background(), client.HermezClient{}, coordinators, ProbeOptions{}); !errors.Is(err, ErrEthClientNotSet) {
+		t.Errorf("without Ethereum client: %v", err)
+	}
+}
+
+func TestProbeCoordinatorsHonorContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	coordinators := []Coordinator{{URL: srv.URL}, {URL: srv.URL}, {URL: srv.URL}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	probes := ProbeCoordinators(ctx, coordinators, ProbeOptions{})
+	if elapsed := time.Since(start); elapsed > DefaultProbeTimeout/2 {
+		t.Errorf("probes kept running after the context was canceled, took %s", elapsed)
+	}
+	for _, probe := range probes {
+		if probe.Reachable || probe.Err == nil {
+			t.Errorf("probe of a coordinator that doesn't answer: %+v", probe)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dghubble/sling"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-node/db/historydb"
+	"github.com/hermeznetwork/hermez-node/eth/contracts/hermez"
+)
+
+const (
+	// DefaultMaxBatchesBehind is how many batches the last batch of a node can be behind the Rollup contract and still
+	// be healthy
+	DefaultMaxBatchesBehind = 1
+	// DefaultHealthCheckTimeout is how long the RequireSyncedNode check waits for the node and the Ethereum client
+	DefaultHealthCheckTimeout = 30 * time.Second
+)
+
+var (
+	// ErrNodeLagging is returned when the node is further behind than the HealthThresholds allow
+	ErrNodeLagging = errors.New("hermez node is lagging")
+	// ErrEthClientNotSet is returned when the HermezClient has no Ethereum client to compare the node with
+	ErrEthClientNotSet = errors.New("ethereum client is not set")
+	// ErrAuctionContractNotSet is returned when the HermezClient has no Auction contract to find the Rollup contract
+	ErrAuctionContractNotSet = errors.New("auction contract is not set")
+)
+
+// HealthThresholds is how far a node can be behind and still be healthy. Zero values take DefaultMaxBlocksBehind and
+// DefaultMaxBatchesBehind.
+type HealthThresholds struct {
+	MaxBlocksBehind  int64
+	MaxBatchesBehind int64
+}
+
+// NodeHealth compares the state of a hermez node with the Ethereum node of the HermezClient. BlocksBehind is how far
+// the last block synced by the node is from the Ethereum head, BatchesBehind how far its last batch is from the last
+// batch forged in the Rollup contract. Issues lists why the node isn't healthy.
+type NodeHealth struct {
+	URL               string
+	EthHeadBlock      int64
+	LastSyncBlock     int64
+	BlocksBehind      int64
+	ContractLastBatch int64
+	NodeLastBatch     int64
+	BatchesBehind     int64
+	Thresholds        HealthThresholds
+	Healthy           bool
+	Issues            []string
+}
+
+// Err returns an error wrapping ErrNodeLagging when the node isn't healthy, nil otherwise
+func (h NodeHealth) Err() error {
+	if h.Healthy {
+		return nil
+	}
+	return fmt.Errorf("%w: %s - %s", ErrNodeLagging, h.URL, strings.Join(h.Issues, ", "))
+}
+
+// CheckNodeHealth pulls the /v1/state of the hermez node at nodeURL and compares it with the Ethereum head and the
+// Rollup contract read through hezClient.EthClient. Every request is bound to ctx.
+func CheckNodeHealth(ctx context.Context, hezClient client.HermezClient, nodeURL string, thresholds HealthThresholds) (health NodeHealth, err error) {
+	if hezClient.EthClient == nil {
+		err = fmt.Errorf("[Node][CheckNodeHealth] Error: %w", ErrEthClientNotSet)
+		return
+	}
+	if hezClient.AuctionContract == nil {
+		err = fmt.Errorf("[Node][CheckNodeHealth] Error: %w", ErrAuctionContractNotSet)
+		return
+	}
+	if len(nodeURL) < 10 {
+		err = fmt.Errorf("[Node][CheckNodeHealth] Invalid node URL: %s", nodeURL)
+		return
+	}
+
+	nodeState, err := getNodeState(ctx, nodeURL, DefaultProbeTimeout)
+	if err != nil {
+		err = fmt.Errorf("[Node][CheckNodeHealth] Error: %w", err)
+		return
+	}
+	headBlock, err := hezClient.EthClient.BlockNumber(ctx)
+	if err != nil {
+		err = fmt.Errorf("[Node][CheckNodeHealth] Error reading Ethereum head - Error: %s", err.Error())
+		return
+	}
+	callOpts := &bind.CallOpts{Context: ctx}
+	rollupAddress, err := hezClient.AuctionContract.HermezRollup(callOpts)
+	if err != nil {
+		err = fmt.Errorf("[Node][CheckNodeHealth] Error reading Rollup contract address - Error: %s", err.Error())
+		return
+	}
+	rollup, err := hermez.NewHermez(rollupAddress, hezClient.EthClient)
+	if err != nil {
+		err = fmt.Errorf("[Node][CheckNodeHealth] Error binding Rollup contract - Error: %s", err.Error())
+		return
+	}
+	lastForgedBatch, err := rollup.LastForgedBatch(callOpts)
+	if err != nil {
+		err = fmt.Errorf("[Node][CheckNodeHealth] Error reading last forged batch - Error: %s", err.Error())
+		return
+	}
+
+	health = evaluateNodeHealth(nodeURL, nodeState, int64(headBlock), int64(lastForgedBatch), thresholds)
+	return
+}
+
+// evaluateNodeHealth compares the state of the node with the Ethereum head and the last batch of the Rollup contract
+func evaluateNodeHealth(nodeURL string, nodeState historydb.StateAPI, ethHeadBlock, contractLastBatch int64,
+	thresholds HealthThresholds) (health NodeHealth) {
+	if thresholds.MaxBlocksBehind <= 0 {
+		thresholds.MaxBlocksBehind = DefaultMaxBlocksBehind
+	}
+	if thresholds.MaxBatchesBehind <= 0 {
+		thresholds.MaxBatchesBehind = DefaultMaxBatchesBehind
+	}
+	health.URL = nodeURL
+	health.Thresholds = thresholds
+	health.EthHeadBlock = ethHeadBlock
+	health.LastSyncBlock = nodeState.Network.LastSyncBlock
+	health.BlocksBehind = health.EthHeadBlock - health.LastSyncBlock
+	health.ContractLastBatch = contractLastBatch
+	if nodeState.Network.LastBatch != nil {
+		health.NodeLastBatch = int64(nodeState.Network.LastBatch.BatchNum)
+	}
+	health.BatchesBehind = health.ContractLastBatch - health.NodeLastBatch
+	if health.BlocksBehind > thresholds.MaxBlocksBehind {
+		health.Issues = append(health.Issues, fmt.Sprintf("synced block %d is %d blocks behind the Ethereum head %d",
+			health.LastSyncBlock, health.BlocksBehind, health.EthHeadBlock))
+	}
+	if health.BatchesBehind > thresholds.MaxBatchesBehind {
+		health.Issues = append(health.Issues, fmt.Sprintf("last batch %d is %d batches behind the Rollup contract batch %d",
+			health.NodeLastBatch, health.BatchesBehind, health.ContractLastBatch))
+	}
+	health.Healthy = len(health.Issues) == 0
+	return
+}
+
+// CheckBootCoordinatorHealth checks the health of hezClient.BootCoordinatorURL
+func CheckBootCoordinatorHealth(ctx context.Context, hezClient client.HermezClient, thresholds HealthThresholds) (NodeHealth, error) {
+	return CheckNodeHealth(ctx, hezClient, hezClient.BootCoordinatorURL, thresholds)
+}
+
+// CheckCurrentCoordinatorHealth checks the health of hezClient.CurrentCoordinatorURL
+func CheckCurrentCoordinatorHealth(ctx context.Context, hezClient client.HermezClient, thresholds HealthThresholds) (NodeHealth, error) {
+	return CheckNodeHealth(ctx, hezClient, hezClient.CurrentCoordinatorURL, thresholds)
+}
+
+// RequireSyncedNode returns a client.HermezClient SubmitCheck that refuses to submit txs to a current coordinator
+// whose health check fails or that is further behind than thresholds. The refusal wraps ErrNodeLagging. Each check
+// gives up after DefaultHealthCheckTimeout.
+func RequireSyncedNode(thresholds HealthThresholds) func(hezClient client.HermezClient) error {
+	return func(hezClient client.HermezClient) error {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultHealthCheckTimeout)
+		defer cancel()
+		health, err := CheckCurrentCoordinatorHealth(ctx, hezClient, thresholds)
+		if err != nil {
+			return fmt.Errorf("[Node][RequireSyncedNode] Error: %w", err)
+		}
+		if err = health.Err(); err != nil {
+			return fmt.Errorf("[Node][RequireSyncedNode] Error: %w", err)
+		}
+		return nil
+	}
+}
+
+// getNodeState pulls the /v1/state of the hermez node at nodeURL, waiting up to timeout or until ctx is done
+func getNodeState(ctx context.Context, nodeURL string, timeout time.Duration) (nodeState historydb.StateAPI, err error) {
+	httpClient := client.NewHttpClient()
+	httpClient.Timeout = timeout
+	nodeClient := sling.New().Base(nodeURL).Client(&httpClient)
+	req, err := nodeClient.New().Get("/v1/state").Request()
+	if err != nil {
+		err = fmt.Errorf("[Node][getNodeState] Error creating request: %s", err.Error())
+		return
+	}
+	req = req.WithContext(ctx)
+	var failureBody interface{}
+	res, err := nodeClient.Do(req, &nodeState, &failureBody)
+	if err != nil {
+		err = fmt.Errorf("[Node][getNodeState] Error pulling state: %s - Error: %s", nodeURL, err.Error())
+		return
+	}
+	if res.StatusCode != http.StatusOK {
+		err = fmt.Errorf("[Node][getNodeState] Error pulling state from hermez node: %+v - Error: %d", failureBody, res.StatusCode)
+		return
+	}
+	return
+}
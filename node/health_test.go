@@ -0,0 +1,106 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/db/historydb"
+)
+
+const testNodeURL = "http://hermez.test"
+
+// testNodeState is the /v1/state of a node synced up to lastSyncBlock with lastBatch as its last batch, 0 for none
+func testNodeState(lastSyncBlock, lastBatch int64) (nodeState historydb.StateAPI) {
+	nodeState.Network.LastSyncBlock = lastSyncBlock
+	if lastBatch > 0 {
+		nodeState.Network.LastBatch = &historydb.BatchAPI{BatchNum: hezCommon.BatchNum(lastBatch)}
+	}
+	return
+}
+
+func TestEvaluateNodeHealth(t *testing.T) {
+	tests := []struct {
+		name              string
+		nodeState         historydb.StateAPI
+		ethHeadBlock      int64
+		contractLastBatch int64
+		thresholds        HealthThresholds
+		blocksBehind      int64
+		batchesBehind     int64
+		issues            int
+	}{
+		{"synced", testNodeState(1000, 50), 1000, 50, HealthThresholds{}, 0, 0, 0},
+		{"at the thresholds", testNodeState(990, 48), 1000, 50, HealthThresholds{MaxBlocksBehind: 10, MaxBatchesBehind: 2}, 10, 2, 0},
+		{"blocks behind", testNodeState(989, 50), 1000, 50, HealthThresholds{MaxBlocksBehind: 10}, 11, 0, 1},
+		{"batches behind", testNodeState(1000, 47), 1000, 50, HealthThresholds{MaxBatchesBehind: 2}, 0, 3, 1},
+		{"both behind", testNodeState(900, 40), 1000, 50, HealthThresholds{}, 100, 10, 2},
+		{"default thresholds", testNodeState(1000-DefaultMaxBlocksBehind-1, 50-DefaultMaxBatchesBehind), 1000, 50,
+			HealthThresholds{}, DefaultMaxBlocksBehind + 1, DefaultMaxBatchesBehind, 1},
+		{"no batch yet", testNodeState(1000, 0), 1000, 5, HealthThresholds{}, 0, 5, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			health := evaluateNodeHealth(testNodeURL, test.nodeState, test.ethHeadBlock, test.contractLastBatch, test.thresholds)
+			if health.BlocksBehind != test.blocksBehind || health.BatchesBehind != test.batchesBehind {
+				t.Errorf("blocks behind %d, batches behind %d, expected %d and %d", health.BlocksBehind, health.BatchesBehind,
+					test.blocksBehind, test.batchesBehind)
+			}
+			if len(health.Issues) != test.issues || health.Healthy != (test.issues == 0) {
+				t.Errorf("healthy: %t - issues: %v, expected %d issues", health.Healthy, health.Issues, test.issues)
+			}
+			if health.Thresholds.MaxBlocksBehind <= 0 || health.Thresholds.MaxBatchesBehind <= 0 {
+				t.Errorf("thresholds without defaults: %+v", health.Thresholds)
+			}
+			err := health.Err()
+			if health.Healthy && err != nil {
+				t.Errorf("healthy node with error: %s", err)
+			}
+			if !health.Healthy && !errors.Is(err, ErrNodeLagging) {
+				t.Errorf("lagging node error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckNodeHealthMissingClients(t *testing.T) {
+	var hezClient client.HermezClient
+	if _, err := CheckNodeHealth(context.Background(), hezClient, testNodeURL, HealthThresholds{}); !errors.Is(err, ErrEthClientNotSet) {
+		t.Errorf("without Ethereum client: %v", err)
+	}
+
+	// dialing over HTTP doesn't connect, the health check must fail before using the client
+	ethClient, err := ethclient.Dial("http://127.0.0.1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ethClient.Close()
+	hezClient.EthClient = ethClient
+	_, err = CheckNodeHealth(context.Background(), hezClient, testNodeURL, HealthThresholds{})
+	if !errors.Is(err, ErrAuctionContractNotSet) || errors.Is(err, ErrEthClientNotSet) {
+		t.Errorf("without Auction contract: %v", err)
+	}
+}
+
+func TestGetNodeStateHonorsContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := getNodeState(ctx, srv.URL, DefaultProbeTimeout); err == nil {
+		t.Fatal("getNodeState of a node that doesn't answer succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > DefaultProbeTimeout/2 {
+		t.Errorf("getNodeState ignored the context deadline, took %s", elapsed)
+	}
+}
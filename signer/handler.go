@@ -0,0 +1,125 @@
+package signer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+)
+
+// handler serves the remote signer API on top of local signers
+type handler struct {
+	bjjSigner account.BJJSigner
+	ethSigner account.EthSigner
+}
+
+// NewHandler returns the reference HTTP handler of the remote signer API used by RemoteSigner. Any of the signers can
+// be nil when the service doesn't hold that kind of key. Serve it only on a loopback address or a Unix socket:
+//
+//	listener, _ := net.Listen("unix", "/var/run/hermez-signer.sock")
+//	http.Serve(listener, signer.NewHandler(bjjWallet, bjjWallet))
+func NewHandler(bjjSigner account.BJJSigner, ethSigner account.EthSigner) http.Handler {
+	h := &handler{
+		bjjSigner: bjjSigner,
+		ethSigner: ethSigner,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(infoPath, h.info)
+	mux.HandleFunc(signPoseidonPath, h.signPoseidon)
+	mux.HandleFunc(signHashPath, h.signHash)
+	return mux
+}
+
+func (h *handler) info(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	var info Info
+	if h.ethSigner != nil {
+		ethAddress := h.ethSigner.EthAddress()
+		info.EthAddress = &ethAddress
+	}
+	if h.bjjSigner != nil {
+		bjjPublicKey := h.bjjSigner.BJJPublicKey()
+		info.BJJPublicKey = &bjjPublicKey
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (h *handler) signPoseidon(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	if h.bjjSigner == nil {
+		writeError(w, http.StatusNotFound, ErrKeyNotAvailable)
+		return
+	}
+	var req SignPoseidonRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	msg, ok := big.NewInt(0).SetString(req.Message, 10)
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid message: %s", req.Message))
+		return
+	}
+	signature, err := h.bjjSigner.SignPoseidon(msg)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, SignPoseidonResponse{Signature: signature.Compress()})
+}
+
+func (h *handler) signHash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	if h.ethSigner == nil {
+		writeError(w, http.StatusNotFound, ErrKeyNotAvailable)
+		return
+	}
+	var req SignHashRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(req.Hash) != common.HashLength {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid hash length: %d", len(req.Hash)))
+		return
+	}
+	signature, err := h.ethSigner.SignHash(req.Hash)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, SignHashResponse{Signature: signature})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, err error) {
+	writeJSON(w, statusCode, ErrorResponse{Message: err.Error()})
+}
+
+// recoverAddress returns the Ethereum address that produced the [R || S || V] signature of the hash
+func recoverAddress(hash []byte, signature []byte) (address common.Address, err error) {
+	publicKey, err := crypto.SigToPub(hash, signature)
+	if err != nil {
+		return
+	}
+	address = crypto.PubkeyToAddress(*publicKey)
+	return
+}
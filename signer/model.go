@@ -0,0 +1,44 @@
+package signer
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+const (
+	infoPath         = "/v1/signer"
+	signPoseidonPath = "/v1/sign/poseidon"
+	signHashPath     = "/v1/sign/hash"
+)
+
+// Info describes the keys a remote signer holds. A nil field means the signer doesn't hold that kind of key
+type Info struct {
+	EthAddress   *common.Address        `json:"ethAddress,omitempty"`
+	BJJPublicKey *babyjub.PublicKeyComp `json:"bjjPublicKey,omitempty"`
+}
+
+// SignPoseidonRequest asks the remote signer to sign a Poseidon hash (decimal string) with the BJJ key
+type SignPoseidonRequest struct {
+	Message string `json:"message"`
+}
+
+// SignPoseidonResponse is the compressed BJJ signature of a SignPoseidonRequest
+type SignPoseidonResponse struct {
+	Signature babyjub.SignatureComp `json:"signature"`
+}
+
+// SignHashRequest asks the remote signer to sign a 32 bytes hash with the Ethereum key
+type SignHashRequest struct {
+	Hash hexutil.Bytes `json:"hash"`
+}
+
+// SignHashResponse is the [R || S || V] Ethereum signature of a SignHashRequest, with V as 0 or 1
+type SignHashResponse struct {
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// ErrorResponse is returned by the remote signer when a request fails
+type ErrorResponse struct {
+	Message string `json:"message"`
+}
@@ -0,0 +1,149 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dghubble/sling"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+const (
+	unixEndpointPrefix = "unix://"
+	defaultTimeoutCall = 30 * time.Second
+)
+
+var (
+	// ErrKeyNotAvailable is returned when the remote signer doesn't hold the kind of key needed to sign
+	ErrKeyNotAvailable = errors.New("remote signer doesn't hold this key")
+	// ErrInvalidSignature is returned when the remote signer answers with a signature that doesn't match its key
+	ErrInvalidSignature = errors.New("remote signer returned an invalid signature")
+)
+
+// RemoteSigner is an account.BJJSigner and account.EthSigner that asks a signing service to sign, so the keys never
+// reach the application. The service must implement the API served by NewHandler.
+type RemoteSigner struct {
+	endpoint     string
+	client       *sling.Sling
+	ethAddress   *common.Address
+	bjjPublicKey *babyjub.PublicKeyComp
+}
+
+var _ account.BJJSigner = (*RemoteSigner)(nil)
+var _ account.EthSigner = (*RemoteSigner)(nil)
+
+// NewRemoteSigner connects to the signing service at endpoint and pulls the keys it holds. The endpoint is an HTTP URL
+// (http://127.0.0.1:8545) or a Unix socket path prefixed by unix:// (unix:///var/run/hermez-signer.sock)
+func NewRemoteSigner(endpoint string) (remoteSigner *RemoteSigner, err error) {
+	httpClient := &http.Client{Timeout: defaultTimeoutCall}
+	baseURL := endpoint
+	if strings.HasPrefix(endpoint, unixEndpointPrefix) {
+		socketPath := strings.TrimPrefix(endpoint, unixEndpointPrefix)
+		httpClient.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socketPath)
+			},
+		}
+		baseURL = "http://unix"
+	}
+	remoteSigner = &RemoteSigner{
+		endpoint: endpoint,
+		client:   sling.New().Base(baseURL).Client(httpClient),
+	}
+
+	var info Info
+	if err = remoteSigner.do(remoteSigner.client.New().Get(infoPath), &info); err != nil {
+		err = fmt.Errorf("[NewRemoteSigner] Error pulling signer info from %s - Error: %w", endpoint, err)
+		return nil, err
+	}
+	if info.EthAddress == nil && info.BJJPublicKey == nil {
+		err = fmt.Errorf("[NewRemoteSigner] Signer %s - Error: %w", endpoint, ErrKeyNotAvailable)
+		return nil, err
+	}
+	remoteSigner.ethAddress = info.EthAddress
+	remoteSigner.bjjPublicKey = info.BJJPublicKey
+	return
+}
+
+// BJJPublicKey returns the compressed BJJ public key of the remote signer, or an empty key when it has none
+func (s *RemoteSigner) BJJPublicKey() babyjub.PublicKeyComp {
+	if s.bjjPublicKey == nil {
+		return babyjub.PublicKeyComp{}
+	}
+	return *s.bjjPublicKey
+}
+
+// SignPoseidon asks the remote signer to sign msg with its BJJ key and verifies the returned signature
+func (s *RemoteSigner) SignPoseidon(msg *big.Int) (signature *babyjub.Signature, err error) {
+	if s.bjjPublicKey == nil {
+		err = fmt.Errorf("[RemoteSigner][SignPoseidon] Signer %s - Error: %w", s.endpoint, ErrKeyNotAvailable)
+		return
+	}
+	var res SignPoseidonResponse
+	req := SignPoseidonRequest{Message: msg.String()}
+	if err = s.do(s.client.New().Post(signPoseidonPath).BodyJSON(req), &res); err != nil {
+		err = fmt.Errorf("[RemoteSigner][SignPoseidon] Error signing with %s - Error: %w", s.endpoint, err)
+		return
+	}
+	signature, err = res.Signature.Decompress()
+	if err != nil {
+		err = fmt.Errorf("[RemoteSigner][SignPoseidon] Signer %s - Error: %w", s.endpoint, ErrInvalidSignature)
+		return nil, err
+	}
+	publicKey, err := s.bjjPublicKey.Decompress()
+	if err != nil || !publicKey.VerifyPoseidon(msg, signature) {
+		err = fmt.Errorf("[RemoteSigner][SignPoseidon] Signer %s - Error: %w", s.endpoint, ErrInvalidSignature)
+		return nil, err
+	}
+	return
+}
+
+// EthAddress returns the Ethereum address of the remote signer, or an empty address when it has none
+func (s *RemoteSigner) EthAddress() common.Address {
+	if s.ethAddress == nil {
+		return common.Address{}
+	}
+	return *s.ethAddress
+}
+
+// SignHash asks the remote signer to sign the hash with its Ethereum key and verifies the returned signature
+func (s *RemoteSigner) SignHash(hash []byte) (signature []byte, err error) {
+	if s.ethAddress == nil {
+		err = fmt.Errorf("[RemoteSigner][SignHash] Signer %s - Error: %w", s.endpoint, ErrKeyNotAvailable)
+		return
+	}
+	var res SignHashResponse
+	if err = s.do(s.client.New().Post(signHashPath).BodyJSON(SignHashRequest{Hash: hash}), &res); err != nil {
+		err = fmt.Errorf("[RemoteSigner][SignHash] Error signing with %s - Error: %w", s.endpoint, err)
+		return
+	}
+	if recovered, errRecover := recoverAddress(hash, res.Signature); errRecover != nil || recovered != *s.ethAddress {
+		err = fmt.Errorf("[RemoteSigner][SignHash] Signer %s - Error: %w", s.endpoint, ErrInvalidSignature)
+		return
+	}
+	signature = res.Signature
+	return
+}
+
+// do sends the request and decodes the successful response into successV
+func (s *RemoteSigner) do(request *sling.Sling, successV interface{}) error {
+	req, err := request.Request()
+	if err != nil {
+		return err
+	}
+	var failureBody ErrorResponse
+	res, err := s.client.Do(req, successV, &failureBody)
+	if res != nil && (res.StatusCode < 200 || res.StatusCode > 299) {
+		return fmt.Errorf("HTTP %d: %s", res.StatusCode, failureBody.Message)
+	}
+	return err
+}
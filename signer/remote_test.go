@@ -0,0 +1,130 @@
+package signer
+
+import (
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/internal/testutil"
+	"github.com/hermeznetwork/hermez-go-sdk/internal/testwallet"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+// impostor announces the keys of a wallet but signs with the keys of another one
+type impostor struct {
+	announced account.BJJWallet
+	signing   account.BJJWallet
+}
+
+func (i impostor) BJJPublicKey() babyjub.PublicKeyComp { return i.announced.BJJPublicKey() }
+
+func (i impostor) SignPoseidon(msg *big.Int) (*babyjub.Signature, error) {
+	return i.signing.SignPoseidon(msg)
+}
+
+func (i impostor) EthAddress() common.Address { return i.announced.EthAddress() }
+
+func (i impostor) SignHash(hash []byte) ([]byte, error) { return i.signing.SignHash(hash) }
+
+// serveUnix serves the handler on a Unix socket and returns its unix:// endpoint
+func serveUnix(t *testing.T, handler http.Handler) string {
+	socketPath := filepath.Join(t.TempDir(), "signer.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("listening on %s: %s", socketPath, err)
+	}
+	srv := &http.Server{Handler: handler}
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return unixEndpointPrefix + socketPath
+}
+
+func TestRemoteSigner(t *testing.T) {
+	wallet := testwallet.New(t, testutil.PvtKeyA)
+	handler := NewHandler(wallet, wallet)
+	httpSrv := httptest.NewServer(handler)
+	defer httpSrv.Close()
+
+	for name, endpoint := range map[string]string{"http": httpSrv.URL, "unix": serveUnix(t, handler)} {
+		t.Run(name, func(t *testing.T) {
+			remoteSigner, err := NewRemoteSigner(endpoint)
+			if err != nil {
+				t.Fatalf("NewRemoteSigner: %s", err)
+			}
+			if remoteSigner.BJJPublicKey() != wallet.BJJPublicKey() || remoteSigner.EthAddress() != wallet.EthAddress() {
+				t.Fatalf("remote keys %s %s, expected %s %s", remoteSigner.BJJPublicKey(), remoteSigner.EthAddress().Hex(),
+					wallet.BJJPublicKey(), wallet.EthAddress().Hex())
+			}
+
+			msg := big.NewInt(123456789)
+			signature, err := remoteSigner.SignPoseidon(msg)
+			if err != nil {
+				t.Fatalf("SignPoseidon: %s", err)
+			}
+			bjjPubKeyComp := wallet.BJJPublicKey()
+			publicKey, err := bjjPubKeyComp.Decompress()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !publicKey.VerifyPoseidon(msg, signature) {
+				t.Fatal("SignPoseidon returned a signature that doesn't verify")
+			}
+
+			hash := crypto.Keccak256([]byte("hermez"))
+			ethSignature, err := remoteSigner.SignHash(hash)
+			if err != nil {
+				t.Fatalf("SignHash: %s", err)
+			}
+			if recovered, err := recoverAddress(hash, ethSignature); err != nil || recovered != wallet.EthAddress() {
+				t.Fatalf("SignHash signature recovers %s - Error: %v", recovered.Hex(), err)
+			}
+		})
+	}
+}
+
+func TestRemoteSignerRejectsWrongKey(t *testing.T) {
+	signer := impostor{announced: testwallet.New(t, testutil.PvtKeyA), signing: testwallet.New(t, testutil.PvtKeyB)}
+	srv := httptest.NewServer(NewHandler(signer, signer))
+	defer srv.Close()
+
+	remoteSigner, err := NewRemoteSigner(srv.URL)
+	if err != nil {
+		t.Fatalf("NewRemoteSigner: %s", err)
+	}
+	if _, err = remoteSigner.SignPoseidon(big.NewInt(42)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("SignPoseidon with the wrong key: %v", err)
+	}
+	if _, err = remoteSigner.SignHash(crypto.Keccak256([]byte("hermez"))); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("SignHash with the wrong key: %v", err)
+	}
+}
+
+func TestRemoteSignerMissingKey(t *testing.T) {
+	wallet := testwallet.New(t, testutil.PvtKeyA)
+	srv := httptest.NewServer(NewHandler(wallet, nil))
+	defer srv.Close()
+
+	remoteSigner, err := NewRemoteSigner(srv.URL)
+	if err != nil {
+		t.Fatalf("NewRemoteSigner: %s", err)
+	}
+	if _, err = remoteSigner.SignHash(crypto.Keccak256([]byte("hermez"))); !errors.Is(err, ErrKeyNotAvailable) {
+		t.Errorf("SignHash without Ethereum key: %v", err)
+	}
+	if _, err = remoteSigner.SignPoseidon(big.NewInt(42)); err != nil {
+		t.Errorf("SignPoseidon: %s", err)
+	}
+
+	empty := httptest.NewServer(NewHandler(nil, nil))
+	defer empty.Close()
+	if _, err = NewRemoteSigner(empty.URL); !errors.Is(err, ErrKeyNotAvailable) {
+		t.Errorf("NewRemoteSigner without keys: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package transaction
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"strconv"
@@ -11,15 +12,31 @@ import (
 
 	ethCommon "github.com/ethereum/go-ethereum/common"
 	hezCommon "github.com/hermeznetwork/hermez-node/common"
+	"github.com/iden3/go-iden3-crypto/babyjub"
 )
 
+// ErrAtomicTxTypeNotSupported is returned when an AtomicTxItem has a tx type that can't be part of an atomic group
+var ErrAtomicTxTypeNotSupported = errors.New("tx type not supported in atomic groups")
+
+// AtomicTxItem is the basic information of a tx of an atomic group. TxType is the type of the tx, Transfer when
+// empty. Transfers go to the Recipient.Idx account or, when not set, to the account of ReceiverAddress for the token.
+// TransferToEthAddr and TransferToBJJ go to Recipient.EthAddr and Recipient.BJJ, and Exit has no recipient.
 type AtomicTxItem struct {
-	SenderBjjWallet       account.BJJWallet
+	SenderBjjWallet       account.BJJSigner
 	ReceiverAddress       string
 	TokenSymbolToTransfer string
 	Amount                *big.Int
 	FeeRangeSelectedID    int
 	RqOffSet              int
+	TxType                hezCommon.TxType
+	Recipient             AtomicRecipient
+}
+
+// AtomicRecipient is the typed receiver of an AtomicTxItem, only the field matching the tx type is used
+type AtomicRecipient struct {
+	Idx     hezCommon.Idx
+	EthAddr ethCommon.Address
+	BJJ     babyjub.PublicKeyComp
 }
 
 func getAccountDetails(hezClient client.HermezClient, address string,
@@ -58,75 +75,87 @@ func getAccountDetails(hezClient client.HermezClient, address string,
 func CreateFullTxs(hezClient client.HermezClient, txs []AtomicTxItem) (fullTxs []hezCommon.PoolL2Tx, err error) {
 	// configure transactions and do basic validations
 	for currentAtomicTxId := range txs {
-		localTx := hezCommon.PoolL2Tx{}
-		localTx.ToEthAddr = ethCommon.HexToAddress(txs[currentAtomicTxId].ReceiverAddress)
-		localTx.ToBJJ = hezCommon.EmptyBJJComp
-		localTx.Amount = txs[currentAtomicTxId].Amount
-		localTx.Fee = hezCommon.FeeSelector(uint8(txs[currentAtomicTxId].FeeRangeSelectedID))
-		localTx.TokenSymbol = txs[currentAtomicTxId].TokenSymbolToTransfer
-
-		// SenderAccount
-		var idx hezCommon.Idx
-		var nonce hezCommon.Nonce
-		var tokenId hezCommon.TokenID
-		idx, nonce, tokenId, err = getAccountDetails(hezClient, txs[currentAtomicTxId].SenderBjjWallet.EthAccount.Address.Hex(), txs[currentAtomicTxId].TokenSymbolToTransfer)
+		var localTx hezCommon.PoolL2Tx
+		localTx, err = newAtomicPoolL2Tx(hezClient, txs[currentAtomicTxId])
 		if err != nil {
-			err = fmt.Errorf("[AtomicTransfer] Error obtaining sender account details. Account: %s - Error: %s\n", txs[currentAtomicTxId].SenderBjjWallet.EthAccount.Address.Hex(), err.Error())
+			err = fmt.Errorf("[CreateFullTxs] Position: %d - Error: %w", currentAtomicTxId, err)
 			return
 		}
-		localTx.TokenID = tokenId
-		if nonce == 0 {
-			localTx.Nonce = 0
-		} else {
-			localTx.Nonce = nonce + 1
+		fullTxs = append(fullTxs, localTx)
+	}
+
+	// Set the RqFields from the tx each RqOffSet points to
+	for i := range txs {
+		if txs[i].RqOffSet < 0 || txs[i].RqOffSet > 7 {
+			err = &AtomicGroupError{Position: i, Err: ErrAtomicRqOffsetInvalid}
+			return
 		}
+		fullTxs[i].RqOffset = uint8(txs[i].RqOffSet)
+	}
+	if err = LinkAtomicGroupTxs(fullTxs); err != nil {
+		err = fmt.Errorf("[CreateFullTxs] Error linking txs - Error: %w", err)
+		return
+	}
+	return
+}
 
-		localTx.FromIdx = idx
+// newAtomicPoolL2Tx resolves the sender account and the recipient of the item and builds the PoolL2Tx of its type
+func newAtomicPoolL2Tx(hezClient client.HermezClient, item AtomicTxItem) (localTx hezCommon.PoolL2Tx, err error) {
+	localTx.Type = item.TxType
+	if len(localTx.Type) == 0 {
+		localTx.Type = hezCommon.TxTypeTransfer
+	}
+	localTx.ToBJJ = hezCommon.EmptyBJJComp
+	localTx.Amount = item.Amount
+	localTx.Fee = hezCommon.FeeSelector(uint8(item.FeeRangeSelectedID))
+	localTx.TokenSymbol = item.TokenSymbolToTransfer
 
-		// Receiver Account
-		idx, _, _, err = getAccountDetails(hezClient, txs[currentAtomicTxId].ReceiverAddress, txs[currentAtomicTxId].TokenSymbolToTransfer)
-		if err != nil {
-			err = fmt.Errorf("[AtomicTransfer] Error obtaining receipient account details. Account: %s - Error: %s\n", txs[currentAtomicTxId].SenderBjjWallet.EthAccount.Address.Hex(), err.Error())
-			return
+	// SenderAccount
+	var idx hezCommon.Idx
+	var nonce hezCommon.Nonce
+	var tokenId hezCommon.TokenID
+	senderHezBjjAddress := BjjToString(item.SenderBjjWallet.BJJPublicKey())
+	idx, nonce, tokenId, err = getAccountDetails(hezClient, senderHezBjjAddress, item.TokenSymbolToTransfer)
+	if err != nil {
+		err = fmt.Errorf("[AtomicTransfer] Error obtaining sender account details. Account: %s - Error: %s\n", senderHezBjjAddress, err.Error())
+		return
+	}
+	localTx.TokenID = tokenId
+	if nonce == 0 {
+		localTx.Nonce = 0
+	} else {
+		localTx.Nonce = nonce + 1
+	}
+	localTx.FromIdx = idx
+
+	// Recipient
+	switch localTx.Type {
+	case hezCommon.TxTypeTransfer:
+		if item.Recipient.Idx != 0 {
+			localTx.ToIdx = item.Recipient.Idx
+			break
 		}
-		localTx.ToIdx = idx
+		// ToIdx identifies the receiver, the signed ToEthAddr of a Transfer is left empty like in newTransferPoolL2Tx
+		localTx.ToIdx, _, _, err = getAccountDetails(hezClient, item.ReceiverAddress, item.TokenSymbolToTransfer)
 		if err != nil {
+			err = fmt.Errorf("[AtomicTransfer] Error obtaining receipient account details. Account: %s - Error: %s\n", item.ReceiverAddress, err.Error())
 			return
 		}
-
-		hezCommon.NewPoolL2Tx(&localTx)
-		fullTxs = append(fullTxs, localTx)
+	case hezCommon.TxTypeTransferToEthAddr:
+		localTx.ToEthAddr = item.Recipient.EthAddr
+	case hezCommon.TxTypeTransferToBJJ:
+		localTx.ToEthAddr = hezCommon.FFAddr
+		localTx.ToBJJ = item.Recipient.BJJ
+	case hezCommon.TxTypeExit:
+		localTx.ToIdx = hezCommon.Idx(1)
+	default:
+		err = fmt.Errorf("[newAtomicPoolL2Tx] Type: %s - Error: %w", localTx.Type, ErrAtomicTxTypeNotSupported)
+		return
 	}
 
-	// Populate RqID and set the RqFields
-	for i := range txs {
-		position := 0
-		if txs[i].RqOffSet > 0 && txs[i].RqOffSet < 4 {
-			position = i + txs[i].RqOffSet
-		} else {
-			switch txs[i].RqOffSet {
-			case 4:
-				position = i - 4
-			case 5:
-				position = i - 3
-			case 6:
-				position = i - 2
-			case 7:
-				position = i - 1
-			case 0:
-				err = fmt.Errorf("[CreateFullTxs]: Invalid rqOffSet")
-				return
-			}
-		}
-		fullTxs[i].RqFromIdx = fullTxs[position].FromIdx
-		fullTxs[i].RqToIdx = fullTxs[position].ToIdx
-		fullTxs[i].RqToEthAddr = fullTxs[position].ToEthAddr
-		fullTxs[i].RqToBJJ = fullTxs[position].ToBJJ
-		fullTxs[i].RqNonce = fullTxs[position].Nonce
-		fullTxs[i].RqFee = fullTxs[position].Fee
-		fullTxs[i].RqAmount = fullTxs[position].Amount
-		fullTxs[i].RqTokenID = fullTxs[position].TokenID
-		fullTxs[i].RqOffset = uint8(txs[i].RqOffSet)
+	if _, err = hezCommon.NewPoolL2Tx(&localTx); err != nil {
+		err = fmt.Errorf("[newAtomicPoolL2Tx] Type: %s - Invalid recipient: %+v - Error: %s", localTx.Type, item.Recipient, err.Error())
+		return
 	}
 	return
 }
@@ -158,6 +187,10 @@ func AtomicTransfer(hezClient client.HermezClient, txs []AtomicTxItem) (serverRe
 	// set AtomicGroupID
 	atomicGroup = SetAtomicGroupID(atomicGroup)
 	atomicGroupID = atomicGroup.ID
+	if err = ValidateAtomicGroup(atomicGroup, DefaultMaxAtomicGroupSize); err != nil {
+		err = fmt.Errorf("[AtomicTransfer] Invalid atomic group - Error: %w", err)
+		return
+	}
 
 	// Sign the txs
 	for i := range txs {
@@ -167,7 +200,12 @@ func AtomicTransfer(hezClient client.HermezClient, txs []AtomicTxItem) (serverRe
 			err = fmt.Errorf("[AtomicTransfer] Error generating currentAtomicTxItem hash. TX: %+v - Error: %s\n", atomicGroup.Txs[i], err.Error())
 			return
 		}
-		signedTx := txs[i].SenderBjjWallet.PrivateKey.SignPoseidon(txHash)
+		var signedTx *babyjub.Signature
+		signedTx, err = txs[i].SenderBjjWallet.SignPoseidon(txHash)
+		if err != nil {
+			err = fmt.Errorf("[AtomicTransfer] Error signing tx. TX: %+v - Error: %s\n", atomicGroup.Txs[i], err.Error())
+			return
+		}
 		atomicGroup.Txs[i].Signature = signedTx.Compress()
 	}
 
@@ -181,7 +219,9 @@ func AtomicTransfer(hezClient client.HermezClient, txs []AtomicTxItem) (serverRe
 	return
 }
 
-// AtomicTransferJSON receives an array of AtomicTxItems in JSON format, create PoolL2Txs, atomic group, sign and post
+// AtomicTransferJSON receives an array of PoolL2Txs in JSON format, links them, sets the atomic group and posts it.
+// The txs are not signed here, so they must be already signed over their Rq* fields. To sign the txs with local
+// wallets use AtomicTransferFromJSON.
 func AtomicTransferJSON(hezClient client.HermezClient, txsJSON []string) (serverResponse string, atomicGroupID hezCommon.AtomicGroupID, err error) {
 	atomicGroup := hezCommon.AtomicGroup{}
 
@@ -196,39 +236,19 @@ func AtomicTransferJSON(hezClient client.HermezClient, txsJSON []string) (server
 		atomicGroup.Txs = append(atomicGroup.Txs, localTx)
 	}
 
-	// Populate RqID and set the RqFields
-	for i := range atomicGroup.Txs {
-		position := 0
-		if atomicGroup.Txs[i].RqOffset > 0 && atomicGroup.Txs[i].RqOffset < 4 {
-			position = i + int(atomicGroup.Txs[i].RqOffset)
-		} else {
-			switch atomicGroup.Txs[i].RqOffset {
-			case 4:
-				position = i - 4
-			case 5:
-				position = i - 3
-			case 6:
-				position = i - 2
-			case 7:
-				position = i - 1
-			case 0:
-				err = fmt.Errorf("[CreateFullTxs]: Invalid rqOffSet")
-				return
-			}
-		}
-		atomicGroup.Txs[i].RqFromIdx = atomicGroup.Txs[position].FromIdx
-		atomicGroup.Txs[i].RqToIdx = atomicGroup.Txs[position].ToIdx
-		atomicGroup.Txs[i].RqToEthAddr = atomicGroup.Txs[position].ToEthAddr
-		atomicGroup.Txs[i].RqToBJJ = atomicGroup.Txs[position].ToBJJ
-		atomicGroup.Txs[i].RqNonce = atomicGroup.Txs[position].Nonce
-		atomicGroup.Txs[i].RqFee = atomicGroup.Txs[position].Fee
-		atomicGroup.Txs[i].RqAmount = atomicGroup.Txs[position].Amount
-		atomicGroup.Txs[i].RqTokenID = atomicGroup.Txs[position].TokenID
+	// Set the RqFields from the tx each RqOffset points to
+	if err = LinkAtomicGroupTxs(atomicGroup.Txs); err != nil {
+		err = fmt.Errorf("[AtomicTransferJSON] Error linking txs - Error: %w", err)
+		return
 	}
 
 	// set AtomicGroupID
 	atomicGroup = SetAtomicGroupID(atomicGroup)
 	atomicGroupID = atomicGroup.ID
+	if err = ValidateAtomicGroup(atomicGroup, DefaultMaxAtomicGroupSize); err != nil {
+		err = fmt.Errorf("[AtomicTransferJSON] Invalid atomic group - Error: %w", err)
+		return
+	}
 
 	// Post
 	serverResponse, err = SendAtomicTxsGroup(hezClient, atomicGroup)
@@ -239,3 +259,17 @@ func AtomicTransferJSON(hezClient client.HermezClient, txsJSON []string) (server
 
 	return
 }
+
+// rqOffsetToPosition returns the position in a group of length txsLen of the tx linked by the tx at position i
+// with rqOffset: 1 to 3 link the next txs, 4 to 7 link the previous ones (4 is -4, 7 is -1)
+func rqOffsetToPosition(i int, rqOffset uint8, txsLen int) (position int, ok bool) {
+	switch {
+	case rqOffset > 0 && rqOffset < 4:
+		position = i + int(rqOffset)
+	case rqOffset >= 4 && rqOffset < 8:
+		position = i - 8 + int(rqOffset)
+	default:
+		return 0, false
+	}
+	return position, position >= 0 && position < txsLen
+}
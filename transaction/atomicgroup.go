@@ -0,0 +1,265 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+)
+
+// DefaultMaxAtomicGroupSize is the size limit checked by AtomicGroupBuilder and AtomicTransfer. The protocol has no
+// explicit limit, but the whole group must fit in one batch next to the other pending txs.
+const DefaultMaxAtomicGroupSize = 32
+
+var (
+	// ErrAtomicGroupTooSmall is returned when the group has less than two txs, the coordinator refuses them
+	ErrAtomicGroupTooSmall = errors.New("atomic group needs at least two txs")
+	// ErrAtomicGroupTooLarge is returned when the group has more txs than the size limit
+	ErrAtomicGroupTooLarge = errors.New("atomic group exceeds the size limit")
+	// ErrAtomicGroupIDMismatch is returned when the atomic group ID doesn't match its txs
+	ErrAtomicGroupIDMismatch = errors.New("atomic group ID doesn't match its txs")
+	// ErrAtomicTxUnknownRef is returned when a requirement references a tx that doesn't belong to the group
+	ErrAtomicTxUnknownRef = errors.New("required tx doesn't belong to the atomic group")
+	// ErrAtomicTxSelfRequirement is returned when a tx requires itself
+	ErrAtomicTxSelfRequirement = errors.New("tx can't require itself")
+	// ErrAtomicTxRequirementTooFar is returned when the required tx is more than 4 positions before or 3 positions
+	// after the tx, the range RqOffset can express
+	ErrAtomicTxRequirementTooFar = errors.New("required tx is out of the RqOffset range")
+	// ErrAtomicRqOffsetInvalid is returned when RqOffset is greater than 7
+	ErrAtomicRqOffsetInvalid = errors.New("invalid RqOffset, valid values go from 0 to 7")
+	// ErrAtomicRqOffsetOutOfBounds is returned when RqOffset points outside the group
+	ErrAtomicRqOffsetOutOfBounds = errors.New("RqOffset points outside the atomic group")
+	// ErrAtomicRqFieldsMismatch is returned when the Rq* fields of a tx don't describe the tx its RqOffset points to
+	ErrAtomicRqFieldsMismatch = errors.New("rq fields don't match the required tx")
+	// ErrAtomicTxOrphan is returned when a tx could be forged without the rest of the group, or the rest without it.
+	// The coordinator only accepts groups where every tx requires another one and the requirements form a single
+	// cycle through all the txs, so a tx without requirement (RqOffset 0) is reported with this error.
+	ErrAtomicTxOrphan = errors.New("tx is not bound to the rest of the atomic group")
+)
+
+// AtomicGroupError tells which tx of the atomic group has a problem. Use errors.Is to find out the problem.
+type AtomicGroupError struct {
+	Position int
+	Err      error
+}
+
+func (e *AtomicGroupError) Error() string {
+	return fmt.Sprintf("atomic group tx at position %d: %s", e.Position, e.Err.Error())
+}
+
+// Unwrap returns the sentinel error describing the problem
+func (e *AtomicGroupError) Unwrap() error {
+	return e.Err
+}
+
+// AtomicTxRef references a tx added to an AtomicGroupBuilder
+type AtomicTxRef int
+
+// AtomicGroupBuilder builds atomic groups where the requirements are expressed by tx reference. It computes the
+// RqOffset and Rq* fields of every tx, and checks the group before returning it.
+type AtomicGroupBuilder struct {
+	txs          []hezCommon.PoolL2Tx
+	requirements map[AtomicTxRef]AtomicTxRef
+	maxSize      int
+}
+
+// NewAtomicGroupBuilder creates an empty AtomicGroupBuilder with DefaultMaxAtomicGroupSize as size limit
+func NewAtomicGroupBuilder() *AtomicGroupBuilder {
+	return &AtomicGroupBuilder{
+		requirements: make(map[AtomicTxRef]AtomicTxRef),
+		maxSize:      DefaultMaxAtomicGroupSize,
+	}
+}
+
+// SetMaxSize changes the size limit of the group
+func (b *AtomicGroupBuilder) SetMaxSize(maxSize int) *AtomicGroupBuilder {
+	b.maxSize = maxSize
+	return b
+}
+
+// Add appends an unsigned tx to the group and returns its reference. The tx position in the group is the order of
+// the Add calls.
+func (b *AtomicGroupBuilder) Add(tx hezCommon.PoolL2Tx) AtomicTxRef {
+	b.txs = append(b.txs, tx)
+	return AtomicTxRef(len(b.txs) - 1)
+}
+
+// Require sets that tx can only be forged together with requiredTx. Each tx requires at most one tx, a second call
+// replaces the requirement.
+func (b *AtomicGroupBuilder) Require(tx AtomicTxRef, requiredTx AtomicTxRef) *AtomicGroupBuilder {
+	b.requirements[tx] = requiredTx
+	return b
+}
+
+// Build links the txs, sets their TxID, Type and AtomicGroupID and validates the group with ValidateAtomicGroup.
+// The errors are *AtomicGroupError wrapping one of the ErrAtomic* errors.
+func (b *AtomicGroupBuilder) Build() (atomicGroup hezCommon.AtomicGroup, err error) {
+	txs := make([]hezCommon.PoolL2Tx, len(b.txs))
+	copy(txs, b.txs)
+	for ref := range b.requirements {
+		if int(ref) < 0 || int(ref) >= len(txs) {
+			return hezCommon.AtomicGroup{}, &AtomicGroupError{Position: int(ref), Err: ErrAtomicTxUnknownRef}
+		}
+	}
+	for i := range txs {
+		txs[i].RqOffset = 0
+		requiredTx, ok := b.requirements[AtomicTxRef(i)]
+		if !ok {
+			continue
+		}
+		if txs[i].RqOffset, err = positionToRqOffset(i, int(requiredTx), len(txs)); err != nil {
+			return hezCommon.AtomicGroup{}, err
+		}
+	}
+	if err = LinkAtomicGroupTxs(txs); err != nil {
+		return hezCommon.AtomicGroup{}, err
+	}
+	for i := range txs {
+		if _, err = hezCommon.NewPoolL2Tx(&txs[i]); err != nil {
+			err = fmt.Errorf("[AtomicGroupBuilder][Build] Position: %d - Error: %s", i, err.Error())
+			return hezCommon.AtomicGroup{}, err
+		}
+	}
+	atomicGroup = SetAtomicGroupID(hezCommon.AtomicGroup{Txs: txs})
+	if err = ValidateAtomicGroup(atomicGroup, b.maxSize); err != nil {
+		return hezCommon.AtomicGroup{}, err
+	}
+	return
+}
+
+// LinkAtomicGroupTxs sets the Rq* fields of every tx from the tx its RqOffset points to. Txs with RqOffset 0 have no
+// requirement and their Rq* fields are cleared.
+func LinkAtomicGroupTxs(txs []hezCommon.PoolL2Tx) error {
+	for i := range txs {
+		if txs[i].RqOffset == 0 {
+			clearRqFields(&txs[i])
+			continue
+		}
+		position, err := requiredTxPosition(i, txs[i].RqOffset, len(txs))
+		if err != nil {
+			return err
+		}
+		setRqFields(&txs[i], txs[position])
+	}
+	return nil
+}
+
+// ValidateAtomicGroup checks the group size against maxSize, that every RqOffset points inside the group, that the
+// Rq* fields describe the required txs, that no tx is orphan and, once set, the atomic group ID.
+// The errors are *AtomicGroupError wrapping one of the ErrAtomic* errors.
+func ValidateAtomicGroup(atomicGroup hezCommon.AtomicGroup, maxSize int) error {
+	txs := atomicGroup.Txs
+	if len(txs) < 2 {
+		return &AtomicGroupError{Position: len(txs), Err: ErrAtomicGroupTooSmall}
+	}
+	if maxSize > 0 && len(txs) > maxSize {
+		return &AtomicGroupError{Position: maxSize, Err: ErrAtomicGroupTooLarge}
+	}
+	required := make([]int, len(txs))
+	for i := range txs {
+		required[i] = -1
+		if txs[i].RqOffset == 0 {
+			continue
+		}
+		position, err := requiredTxPosition(i, txs[i].RqOffset, len(txs))
+		if err != nil {
+			return err
+		}
+		if !hasRqFields(txs[i], txs[position]) {
+			return &AtomicGroupError{Position: i, Err: ErrAtomicRqFieldsMismatch}
+		}
+		required[i] = position
+	}
+	if orphan := findOrphanTx(required); orphan >= 0 {
+		return &AtomicGroupError{Position: orphan, Err: ErrAtomicTxOrphan}
+	}
+	if atomicGroup.ID != hezCommon.EmptyAtomicGroupID && !atomicGroup.IsAtomicGroupIDValid() {
+		return &AtomicGroupError{Position: 0, Err: ErrAtomicGroupIDMismatch}
+	}
+	return nil
+}
+
+// findOrphanTx returns the position of a tx out of the requirements cycle, or -1 when the requirements form a single
+// cycle through every tx. required[i] is the position required by the tx at i, -1 when it has no requirement.
+func findOrphanTx(required []int) int {
+	for i := range required {
+		if required[i] < 0 {
+			return i
+		}
+	}
+	// every tx requires another one, following the requirements from the first tx must go through every tx and
+	// come back to it
+	visited := make([]bool, len(required))
+	position := 0
+	for !visited[position] {
+		visited[position] = true
+		position = required[position]
+	}
+	if position != 0 {
+		return 0
+	}
+	for i := range visited {
+		if !visited[i] {
+			return i
+		}
+	}
+	return -1
+}
+
+// requiredTxPosition returns the position of the tx required by the tx at position i
+func requiredTxPosition(i int, rqOffset uint8, txsLen int) (int, error) {
+	if rqOffset > 7 {
+		return 0, &AtomicGroupError{Position: i, Err: ErrAtomicRqOffsetInvalid}
+	}
+	position, ok := rqOffsetToPosition(i, rqOffset, txsLen)
+	if !ok {
+		return 0, &AtomicGroupError{Position: i, Err: ErrAtomicRqOffsetOutOfBounds}
+	}
+	return position, nil
+}
+
+// positionToRqOffset returns the RqOffset of the tx at position i requiring the tx at position required
+func positionToRqOffset(i int, required int, txsLen int) (uint8, error) {
+	if required < 0 || required >= txsLen {
+		return 0, &AtomicGroupError{Position: i, Err: ErrAtomicTxUnknownRef}
+	}
+	relative := required - i
+	switch {
+	case relative == 0:
+		return 0, &AtomicGroupError{Position: i, Err: ErrAtomicTxSelfRequirement}
+	case relative > 0 && relative < 4:
+		return uint8(relative), nil
+	case relative < 0 && relative >= -4:
+		return uint8(8 + relative), nil
+	}
+	return 0, &AtomicGroupError{Position: i, Err: ErrAtomicTxRequirementTooFar}
+}
+
+// setRqFields copies the fields of the required tx to the Rq* fields of tx
+func setRqFields(tx *hezCommon.PoolL2Tx, requiredTx hezCommon.PoolL2Tx) {
+	tx.RqFromIdx = requiredTx.FromIdx
+	tx.RqToIdx = requiredTx.ToIdx
+	tx.RqToEthAddr = requiredTx.ToEthAddr
+	tx.RqToBJJ = requiredTx.ToBJJ
+	tx.RqTokenID = requiredTx.TokenID
+	tx.RqAmount = requiredTx.Amount
+	tx.RqFee = requiredTx.Fee
+	tx.RqNonce = requiredTx.Nonce
+}
+
+// clearRqFields removes the requirement of tx
+func clearRqFields(tx *hezCommon.PoolL2Tx) {
+	setRqFields(tx, hezCommon.PoolL2Tx{})
+}
+
+// hasRqFields returns true when the Rq* fields of tx describe requiredTx
+func hasRqFields(tx hezCommon.PoolL2Tx, requiredTx hezCommon.PoolL2Tx) bool {
+	return tx.RqFromIdx == requiredTx.FromIdx &&
+		tx.RqToIdx == requiredTx.ToIdx &&
+		tx.RqToEthAddr == requiredTx.ToEthAddr &&
+		tx.RqToBJJ == requiredTx.ToBJJ &&
+		tx.RqTokenID == requiredTx.TokenID &&
+		bigIntString(tx.RqAmount) == bigIntString(requiredTx.Amount) &&
+		tx.RqFee == requiredTx.Fee &&
+		tx.RqNonce == requiredTx.Nonce
+}
@@ -0,0 +1,209 @@
+package transaction
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+)
+
+var (
+	// ErrAtomicJSONUnknownWallet is returned when a leg is sent from a wallet alias without signer
+	ErrAtomicJSONUnknownWallet = errors.New("unknown wallet alias")
+	// ErrAtomicJSONUnknownLeg is returned when a leg requires a leg ID that isn't defined in the group
+	ErrAtomicJSONUnknownLeg = errors.New("unknown leg ID")
+	// ErrAtomicJSONDuplicatedLeg is returned when two legs have the same ID
+	ErrAtomicJSONDuplicatedLeg = errors.New("duplicated leg ID")
+)
+
+// AtomicGroupJSON is the unsigned atomic group read by AtomicTransferFromJSON:
+//
+//	{
+//	  "legs": [
+//	    {
+//	      "id": "alice-pays",
+//	      "from": "alice",
+//	      "to": "bob",
+//	      "token": "HEZ",
+//	      "amount": "1000000000000000000",
+//	      "fee": 126,
+//	      "requires": "bob-pays"
+//	    },
+//	    {
+//	      "id": "bob-pays",
+//	      "from": "bob",
+//	      "to": "hez:0x263C3Ab7E4832eDF623fBdD66ACee71c028Ff591",
+//	      "token": "USDT",
+//	      "amount": "5000000",
+//	      "fee": 126,
+//	      "requires": "alice-pays"
+//	    }
+//	  ]
+//	}
+//
+// "from" is the alias of the wallet that sends and signs the leg. "to" is either a wallet alias, a hez Ethereum
+// address or a hez BJJ address, the leg is a transfer to its account of the token. "requires" is the id of the leg
+// that must be forged together with this one. When no leg sets "requires", the legs are linked in a single cycle,
+// which is what the coordinator needs to accept the group.
+type AtomicGroupJSON struct {
+	Legs []AtomicLegJSON `json:"legs"`
+}
+
+// AtomicLegJSON is a leg of an AtomicGroupJSON
+type AtomicLegJSON struct {
+	ID       string `json:"id,omitempty"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Token    string `json:"token"`
+	Amount   string `json:"amount"`
+	Fee      int    `json:"fee"`
+	Requires string `json:"requires,omitempty"`
+}
+
+// AtomicLegResult is the tx built for each leg of an AtomicGroupJSON, in the same order
+type AtomicLegResult struct {
+	ID   string
+	From string
+	TxID hezCommon.TxID
+}
+
+// AtomicTransferFromJSON reads an AtomicGroupJSON, resolves and links its legs, sets the AtomicGroupID, signs every
+// leg with the signer of its "from" alias and submits the group with SendAtomicTxsGroup. legs is returned as soon as
+// the group is built, also when the submission fails.
+func AtomicTransferFromJSON(hezClient client.HermezClient, groupJSON []byte, signers map[string]account.BJJSigner) (serverResponse string, atomicGroupID hezCommon.AtomicGroupID, legs []AtomicLegResult, err error) {
+	var group AtomicGroupJSON
+	if err = json.Unmarshal(groupJSON, &group); err != nil {
+		err = fmt.Errorf("[AtomicTransferFromJSON] Error unmarshaling atomic group - Error: %s", err.Error())
+		return
+	}
+	atomicGroup, err := BuildSignedAtomicGroup(hezClient, group, signers)
+	if err != nil {
+		err = fmt.Errorf("[AtomicTransferFromJSON] Error: %w", err)
+		return
+	}
+	atomicGroupID = atomicGroup.ID
+	for i, leg := range group.Legs {
+		legs = append(legs, AtomicLegResult{ID: leg.ID, From: leg.From, TxID: atomicGroup.Txs[i].TxID})
+	}
+
+	serverResponse, err = SendAtomicTxsGroup(hezClient, atomicGroup)
+	if err != nil {
+		err = fmt.Errorf("[AtomicTransferFromJSON] Error sending transactions. Error: %s\n", err.Error())
+		return
+	}
+	return
+}
+
+// BuildSignedAtomicGroup resolves the accounts, nonces and tokens of the legs, links them with an AtomicGroupBuilder
+// and signs every leg with the signer of its "from" alias. The txs keep the order of the legs.
+func BuildSignedAtomicGroup(hezClient client.HermezClient, group AtomicGroupJSON, signers map[string]account.BJJSigner) (atomicGroup hezCommon.AtomicGroup, err error) {
+	positions := make(map[string]int, len(group.Legs))
+	for i, leg := range group.Legs {
+		if len(leg.ID) == 0 {
+			continue
+		}
+		if _, ok := positions[leg.ID]; ok {
+			err = fmt.Errorf("[BuildSignedAtomicGroup] Leg: %s - Error: %w", leg.ID, ErrAtomicJSONDuplicatedLeg)
+			return
+		}
+		positions[leg.ID] = i
+	}
+
+	builder := NewAtomicGroupBuilder()
+	// legs sent from the same account use consecutive nonces
+	nonces := make(map[hezCommon.Idx]hezCommon.Nonce)
+	for i, leg := range group.Legs {
+		var tx hezCommon.PoolL2Tx
+		if tx, err = prepareAtomicLegJSON(hezClient, leg, signers); err != nil {
+			err = fmt.Errorf("[BuildSignedAtomicGroup] Leg %d - Error: %w", i, err)
+			return
+		}
+		if nonce, ok := nonces[tx.FromIdx]; ok {
+			tx.Nonce = nonce + 1
+			tx.TxID = hezCommon.TxID{}
+		}
+		nonces[tx.FromIdx] = tx.Nonce
+		builder.Add(tx)
+	}
+
+	explicit := false
+	for i, leg := range group.Legs {
+		if len(leg.Requires) == 0 {
+			continue
+		}
+		explicit = true
+		required, ok := positions[leg.Requires]
+		if !ok {
+			err = fmt.Errorf("[BuildSignedAtomicGroup] Leg %d requires: %s - Error: %w", i, leg.Requires, ErrAtomicJSONUnknownLeg)
+			return
+		}
+		builder.Require(AtomicTxRef(i), AtomicTxRef(required))
+	}
+	if !explicit && len(group.Legs) > 1 {
+		requireAtomicCycle(builder, len(group.Legs))
+	}
+	atomicGroup, err = builder.Build()
+	if err != nil {
+		err = fmt.Errorf("[BuildSignedAtomicGroup] Error building atomic group - Error: %w", err)
+		return
+	}
+
+	for i, leg := range group.Legs {
+		var txHash *big.Int
+		txHash, err = atomicGroup.Txs[i].HashToSign(uint16(hezClient.EthereumChainID))
+		if err != nil {
+			err = fmt.Errorf("[BuildSignedAtomicGroup] Error generating tx hash. TxID: %s - Error: %s", atomicGroup.Txs[i].TxID.String(), err.Error())
+			return
+		}
+		signature, errSign := signers[leg.From].SignPoseidon(txHash)
+		if errSign != nil {
+			err = fmt.Errorf("[BuildSignedAtomicGroup] Error signing tx. TxID: %s - Error: %w", atomicGroup.Txs[i].TxID.String(), errSign)
+			return
+		}
+		atomicGroup.Txs[i].Signature = signature.Compress()
+	}
+	return
+}
+
+// prepareAtomicLegJSON resolves the sender and receiver accounts of the leg and returns the unsigned transfer
+func prepareAtomicLegJSON(hezClient client.HermezClient, leg AtomicLegJSON, signers map[string]account.BJJSigner) (tx hezCommon.PoolL2Tx, err error) {
+	sender, ok := signers[leg.From]
+	if !ok || sender == nil {
+		err = fmt.Errorf("[prepareAtomicLegJSON] From: %s - Error: %w", leg.From, ErrAtomicJSONUnknownWallet)
+		return
+	}
+	receiver := leg.To
+	if receiverSigner, ok := signers[leg.To]; ok && receiverSigner != nil {
+		receiver = BjjToString(receiverSigner.BJJPublicKey())
+	}
+	amount, ok := new(big.Int).SetString(leg.Amount, 10)
+	if !ok {
+		err = fmt.Errorf("[prepareAtomicLegJSON] Invalid amount: %s", leg.Amount)
+		return
+	}
+	offlineTx, err := PrepareL2Transfer(hezClient, sender, receiver, strings.ToUpper(leg.Token), amount, leg.Fee)
+	if err != nil {
+		return
+	}
+	return offlineTx.PoolL2Tx()
+}
+
+// requireAtomicCycle links the txs in a single cycle whose steps RqOffset can express for any group size: the even
+// positions in ascending order, then the odd ones in descending order, and back to the first tx
+func requireAtomicCycle(builder *AtomicGroupBuilder, txsLen int) {
+	order := make([]AtomicTxRef, 0, txsLen)
+	for i := 0; i < txsLen; i += 2 {
+		order = append(order, AtomicTxRef(i))
+	}
+	for i := txsLen - 1 - txsLen%2; i > 0; i -= 2 {
+		order = append(order, AtomicTxRef(i))
+	}
+	for k := range order {
+		builder.Require(order[k], order[(k+1)%len(order)])
+	}
+}
@@ -0,0 +1,177 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+)
+
+// DefaultAtomicGroupPollInterval is the time WaitForAtomicGroup waits between two status queries
+const DefaultAtomicGroupPollInterval = 10 * time.Second
+
+// ErrAtomicGroupNotFound is returned when the coordinator has no tx of the atomic group
+var ErrAtomicGroupNotFound = errors.New("atomic group not found")
+
+// AtomicGroupState is the state of the whole atomic group, derived from the state of its legs
+type AtomicGroupState string
+
+const (
+	// AtomicGroupStatePending means that some legs are still waiting in the pool or being forged
+	AtomicGroupStatePending AtomicGroupState = "pending"
+	// AtomicGroupStateForged means that every leg was forged
+	AtomicGroupStateForged AtomicGroupState = "forged"
+	// AtomicGroupStateInvalid means that a leg was rejected, so the group will never be forged
+	AtomicGroupStateInvalid AtomicGroupState = "invalid"
+)
+
+// AtomicLegStatus is the pool state of a tx of the atomic group. BatchNum is set once the tx is forged, the error
+// fields once the coordinator rejects it.
+type AtomicLegStatus struct {
+	TxID      hezCommon.TxID
+	State     hezCommon.PoolL2TxState
+	BatchNum  hezCommon.BatchNum
+	ErrorCode int
+	ErrorType string
+	Info      string
+}
+
+// AtomicGroupStatus is the outcome of an atomic group. BatchNum is the batch where the group was forged, 0 until then.
+type AtomicGroupStatus struct {
+	ID       hezCommon.AtomicGroupID
+	State    AtomicGroupState
+	BatchNum hezCommon.BatchNum
+	Legs     []AtomicLegStatus
+}
+
+// IsFinal returns true once the group is forged or invalid, its state won't change anymore
+func (s AtomicGroupStatus) IsFinal() bool {
+	return s.State == AtomicGroupStateForged || s.State == AtomicGroupStateInvalid
+}
+
+// GetAtomicGroup connects to the hezClient.CurrentCoordinatorURL and pull the txs of the atomic group from the pool
+func GetAtomicGroup(hezClient client.HermezClient, atomicGroupID hezCommon.AtomicGroupID) (transactions []PoolTxAPI, err error) {
+	URL := hezClient.CurrentCoordinatorURL + "/v1/atomic-pool/" + atomicGroupID.String()
+	request, err := http.NewRequest("GET", URL, nil)
+	if err != nil {
+		err = fmt.Errorf("[GetAtomicGroup] Error creating HTTP request. URL: %s - Error: %s", URL, err.Error())
+		return
+	}
+	response, err := hezClient.HttpClient.Do(request)
+	if err != nil {
+		err = fmt.Errorf("[GetAtomicGroup] Error submitting HTTP request. URL: %s - Error: %s", URL, err.Error())
+		return
+	}
+	defer response.Body.Close()
+
+	b, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		err = fmt.Errorf("[GetAtomicGroup] Error reading HTTP return from Coordinator. URL: %s - Error: %s", URL, err.Error())
+		return
+	}
+	if response.StatusCode == http.StatusNotFound {
+		err = fmt.Errorf("[GetAtomicGroup] Atomic group: %s - Error: %w", atomicGroupID.String(), ErrAtomicGroupNotFound)
+		return
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		err = fmt.Errorf("[GetAtomicGroup] StatusCode: %d - Returned Message: %s - URL: %s", response.StatusCode, string(b), URL)
+		return
+	}
+	if err = json.Unmarshal(b, &transactions); err != nil {
+		err = fmt.Errorf("[GetAtomicGroup] Error unmarshaling atomic group - Error: %s", err.Error())
+		return
+	}
+	if len(transactions) == 0 {
+		err = fmt.Errorf("[GetAtomicGroup] Atomic group: %s - Error: %w", atomicGroupID.String(), ErrAtomicGroupNotFound)
+		return
+	}
+	return
+}
+
+// GetAtomicGroupStatus pulls the atomic group from the pool and returns the state of the group and of each leg. The
+// batch number of the forged legs is read from the transactions history.
+func GetAtomicGroupStatus(hezClient client.HermezClient, atomicGroupID hezCommon.AtomicGroupID) (status AtomicGroupStatus, err error) {
+	transactions, err := GetAtomicGroup(hezClient, atomicGroupID)
+	if err != nil {
+		return
+	}
+	status.ID = atomicGroupID
+	for _, tx := range transactions {
+		leg := AtomicLegStatus{
+			TxID:      tx.TxID,
+			State:     tx.State,
+			ErrorCode: tx.ErrorCode,
+			ErrorType: tx.ErrorType,
+			Info:      tx.Info,
+		}
+		if leg.State == hezCommon.PoolL2TxStateForged {
+			var forged HistoryTx
+			if forged, _, err = getForgedTx(hezClient, tx.TxID); err != nil {
+				err = fmt.Errorf("[GetAtomicGroupStatus] Atomic group: %s - Error: %w", atomicGroupID.String(), err)
+				return
+			}
+			leg.BatchNum = forged.ForgedBatchNum()
+		}
+		status.Legs = append(status.Legs, leg)
+	}
+	status.setState()
+	return
+}
+
+// WaitForAtomicGroup polls the atomic group status every pollInterval until every leg is forged or the group becomes
+// invalid. When ctx is done it returns the last status read together with the context error.
+func WaitForAtomicGroup(ctx context.Context, hezClient client.HermezClient, atomicGroupID hezCommon.AtomicGroupID, pollInterval time.Duration) (status AtomicGroupStatus, err error) {
+	if pollInterval <= 0 {
+		pollInterval = DefaultAtomicGroupPollInterval
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		var current AtomicGroupStatus
+		current, err = GetAtomicGroupStatus(hezClient, atomicGroupID)
+		if err != nil {
+			err = fmt.Errorf("[WaitForAtomicGroup] Error: %w", err)
+			return
+		}
+		status = current
+		if status.IsFinal() {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			err = fmt.Errorf("[WaitForAtomicGroup] Atomic group: %s - State: %s - Error: %w", atomicGroupID.String(), status.State, ctx.Err())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// setState derives the state of the group from the state of its legs. A forged leg whose batch isn't in the
+// transactions history yet keeps the group pending, so a forged group always has its BatchNum.
+func (s *AtomicGroupStatus) setState() {
+	s.State = AtomicGroupStateForged
+	s.BatchNum = 0
+	for _, leg := range s.Legs {
+		switch {
+		case leg.State == hezCommon.PoolL2TxStateInvalid:
+			s.State = AtomicGroupStateInvalid
+			s.BatchNum = 0
+			return
+		case leg.State == hezCommon.PoolL2TxStateForged && leg.BatchNum > 0:
+			if leg.BatchNum > s.BatchNum {
+				s.BatchNum = leg.BatchNum
+			}
+		default:
+			s.State = AtomicGroupStatePending
+		}
+	}
+	if s.State != AtomicGroupStateForged {
+		s.BatchNum = 0
+	}
+}
@@ -45,7 +45,7 @@ func NewHermezAPITxRequest(poolTx *hezCommon.PoolL2Tx, token hezCommon.Token) AP
 }
 
 // NewSignedAPITxToEthAddr creates and signs a new APITx to transfer to eth addr
-func NewSignedAPITxToEthAddr(chainID int, fromBjjWallet account.BJJWallet, fromIdx uint64, toEthAddress string, amount *big.Int, feeSelector hezCommon.FeeSelector, token hezCommon.Token, nonce int) (APITx, error) {
+func NewSignedAPITxToEthAddr(chainID int, fromBjjWallet account.BJJSigner, fromIdx uint64, toEthAddress string, amount *big.Int, feeSelector hezCommon.FeeSelector, token hezCommon.Token, nonce int) (APITx, error) {
 
 	f40Amount, err := AmountToFloat40(amount)
 	if err != nil {
@@ -67,7 +67,8 @@ func NewSignedAPITxToEthAddr(chainID int, fromBjjWallet account.BJJWallet, fromI
 	return SignAPITx(chainID, fromBjjWallet, token, tx)
 }
 
-func SignAPITx(chainID int, fromBjjWallet account.BJJWallet, token hezCommon.Token, tx *hezCommon.PoolL2Tx) (APITx, error) {
+// SignAPITx signs the PoolL2Tx with the BJJSigner and converts it to Hermez API request model
+func SignAPITx(chainID int, fromBjjWallet account.BJJSigner, token hezCommon.Token, tx *hezCommon.PoolL2Tx) (APITx, error) {
 
 	// log.Println("")
 	// log.Println("[MarshalTransaction] hezcommon.PoolL2Tx")
@@ -86,7 +87,10 @@ func SignAPITx(chainID int, fromBjjWallet account.BJJWallet, token hezCommon.Tok
 
 	// log.Println("[MarshalTransaction] tx signed")
 	// log.Printf("%+v\n\n", tx)
-	signedTx := fromBjjWallet.PrivateKey.SignPoseidon(txHash)
+	signedTx, err := fromBjjWallet.SignPoseidon(txHash)
+	if err != nil {
+		return APITx{}, err
+	}
 	tx.Signature = signedTx.Compress()
 
 	t := hezCommon.Token{
@@ -142,12 +146,29 @@ func BjjToString(bjj babyjub.PublicKeyComp) string {
 func MarshalTransaction(itemToTransfer string,
 	senderAcctDetails account.AccountAPIResponse,
 	receiverAcctDetails account.AccountAPIResponse,
-	senderBjjWallet account.BJJWallet,
+	senderBjjWallet account.BJJSigner,
 	amount *big.Int,
 	feeSelector int,
 	ethereumChainID int) (apiTxRequest APITx, err error) {
 
-	var token hezCommon.Token
+	tx, token, err := newTransferPoolL2Tx(itemToTransfer, senderAcctDetails, receiverAcctDetails, BjjToString(senderBjjWallet.BJJPublicKey()), amount, feeSelector)
+	if err != nil {
+		return
+	}
+
+	apiTxRequest, err = SignAPITx(ethereumChainID, senderBjjWallet, token, tx)
+
+	return
+}
+
+// newTransferPoolL2Tx builds the unsigned Transfer of itemToTransfer between the sender and receiver accounts
+func newTransferPoolL2Tx(itemToTransfer string,
+	senderAcctDetails account.AccountAPIResponse,
+	receiverAcctDetails account.AccountAPIResponse,
+	senderHezBjjAddress string,
+	amount *big.Int,
+	feeSelector int) (tx *hezCommon.PoolL2Tx, token hezCommon.Token, err error) {
+
 	var nonce hezCommon.Nonce
 	var fromIdx, toIdx hezCommon.Idx
 
@@ -186,7 +207,7 @@ func MarshalTransaction(itemToTransfer string,
 
 	// If there is no innerAccount created to this specific token stop the code
 	if len(fromIdx.String()) < 1 {
-		err = fmt.Errorf("[MarshalTransaction] There is no sender Account to this user %s for this Token %s", senderBjjWallet.HezBjjAddress, itemToTransfer)
+		err = fmt.Errorf("[MarshalTransaction] There is no sender Account to this user %s for this Token %s", senderHezBjjAddress, itemToTransfer)
 		log.Println(err.Error())
 		return
 	}
@@ -201,7 +222,7 @@ func MarshalTransaction(itemToTransfer string,
 	// fee := hezcommon.FeeSelector(100)
 	fee := hezCommon.FeeSelector(uint8(feeSelector)) // 10.2%
 
-	tx := new(hezCommon.PoolL2Tx)
+	tx = new(hezCommon.PoolL2Tx)
 	tx.FromIdx = fromIdx
 	tx.ToEthAddr = hezCommon.EmptyAddr
 	tx.ToBJJ = hezCommon.EmptyBJJComp
@@ -212,7 +233,5 @@ func MarshalTransaction(itemToTransfer string,
 	tx.Nonce = nonce
 	tx.Type = hezCommon.TxTypeTransfer
 
-	apiTxRequest, err = SignAPITx(ethereumChainID, senderBjjWallet, token, tx)
-
 	return
 }
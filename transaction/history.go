@@ -0,0 +1,237 @@
+package transaction
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-go-sdk/token"
+	"github.com/hermeznetwork/hermez-go-sdk/util"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/common/apitypes"
+)
+
+// historyPageLimit is the biggest page the hermez node returns
+const historyPageLimit = 2049
+
+// HistoryTx is a forged L1 or L2 tx, or an L1 tx waiting in the queue to be forged, read from the transactions history.
+// L1Info is set for the L1 txs and L2Info for the L2 txs.
+type HistoryTx struct {
+	ItemID      uint64               `json:"itemId"`
+	TxID        hezCommon.TxID       `json:"id"`
+	Type        hezCommon.TxType     `json:"type"`
+	L1orL2      string               `json:"L1orL2"`
+	Position    int                  `json:"position"`
+	FromIdx     *apitypes.HezIdx     `json:"fromAccountIndex"`
+	FromEthAddr *apitypes.HezEthAddr `json:"fromHezEthereumAddress"`
+	FromBJJ     *apitypes.HezBJJ     `json:"fromBJJ"`
+	ToIdx       apitypes.HezIdx      `json:"toAccountIndex"`
+	ToEthAddr   *apitypes.HezEthAddr `json:"toHezEthereumAddress"`
+	ToBJJ       *apitypes.HezBJJ     `json:"toBJJ"`
+	Amount      apitypes.BigIntStr   `json:"amount"`
+	BatchNum    *hezCommon.BatchNum  `json:"batchNum"`
+	HistoricUSD *float64             `json:"historicUSD"`
+	Timestamp   time.Time            `json:"timestamp"`
+	L1Info      *HistoryL1Info       `json:"L1Info"`
+	L2Info      *HistoryL2Info       `json:"L2Info"`
+	Token       token.Token          `json:"token"`
+}
+
+// HistoryL1Info holds the fields of a HistoryTx only L1 txs have
+type HistoryL1Info struct {
+	ToForgeL1TxsNum          *int64              `json:"toForgeL1TransactionsNum"`
+	UserOrigin               *bool               `json:"userOrigin"`
+	DepositAmount            *apitypes.BigIntStr `json:"depositAmount"`
+	AmountSuccess            bool                `json:"amountSuccess"`
+	DepositAmountSuccess     bool                `json:"depositAmountSuccess"`
+	HistoricDepositAmountUSD *float64            `json:"historicDepositAmountUSD"`
+	EthereumBlockNum         int64               `json:"ethereumBlockNum"`
+}
+
+// HistoryL2Info holds the fields of a HistoryTx only L2 txs have
+type HistoryL2Info struct {
+	Fee            *hezCommon.FeeSelector `json:"fee"`
+	HistoricFeeUSD *float64               `json:"historicFeeUSD"`
+	Nonce          *hezCommon.Nonce       `json:"nonce"`
+}
+
+// HistoryTxsAPIResponse is a page of the transactions history
+type HistoryTxsAPIResponse struct {
+	Transactions []HistoryTx `json:"transactions"`
+	PendingItems uint64      `json:"pendingItems"`
+}
+
+// IsL1 returns true for L1 txs, sent through the smart contract
+func (tx HistoryTx) IsL1() bool {
+	return tx.L1orL2 == "L1"
+}
+
+// IsForged returns false for the L1 txs still waiting in the queue
+func (tx HistoryTx) IsForged() bool {
+	return tx.BatchNum != nil && *tx.BatchNum > 0
+}
+
+// ForgedBatchNum returns the batch where the tx was forged, 0 while it isn't forged
+func (tx HistoryTx) ForgedBatchNum() hezCommon.BatchNum {
+	if tx.BatchNum == nil {
+		return 0
+	}
+	return *tx.BatchNum
+}
+
+// HistoryTxFilter selects the txs of the transactions history. Empty fields don't filter. The node accepts either
+// HezEthereumAddress or BJJ, and AccountIndex can't be combined with them nor with TokenID. Order is ASC (default) or
+// DESC, Limit is the page size, up to 2049.
+type HistoryTxFilter struct {
+	HezEthereumAddress string
+	BJJ                string
+	AccountIndex       string
+	TokenID            *hezCommon.TokenID
+	BatchNum           *hezCommon.BatchNum
+	TxType             hezCommon.TxType
+	IncludePendingL1s  bool
+	Order              string
+	Limit              int
+}
+
+// query builds the query string of the filter for the page starting at fromItem, nil for the first page
+func (f HistoryTxFilter) query(fromItem *uint64) string {
+	values := url.Values{}
+	if len(f.HezEthereumAddress) > 0 {
+		values.Set("hezEthereumAddress", withHezPrefix(f.HezEthereumAddress))
+	}
+	if len(f.BJJ) > 0 {
+		values.Set("BJJ", withHezPrefix(f.BJJ))
+	}
+	if len(f.AccountIndex) > 0 {
+		values.Set("accountIndex", withHezPrefix(f.AccountIndex))
+	}
+	if f.TokenID != nil {
+		values.Set("tokenId", strconv.FormatUint(uint64(*f.TokenID), 10))
+	}
+	if f.BatchNum != nil {
+		values.Set("batchNum", strconv.FormatInt(int64(*f.BatchNum), 10))
+	}
+	if len(f.TxType) > 0 {
+		values.Set("type", string(f.TxType))
+	}
+	if f.IncludePendingL1s {
+		values.Set("includePendingL1s", "true")
+	}
+	if len(f.Order) > 0 {
+		values.Set("order", f.Order)
+	}
+	limit := f.Limit
+	if limit <= 0 || limit > historyPageLimit {
+		limit = historyPageLimit
+	}
+	values.Set("limit", strconv.Itoa(limit))
+	if fromItem != nil {
+		values.Set("fromItem", strconv.FormatUint(*fromItem, 10))
+	}
+	return values.Encode()
+}
+
+func withHezPrefix(address string) string {
+	if len(address) > 4 && address[:4] == "hez:" {
+		return address
+	}
+	return "hez:" + address
+}
+
+// GetHistoryTx connects to a hermez node and pull a tx from the transactions history based on it's ID
+func GetHistoryTx(hezClient client.HermezClient, txID hezCommon.TxID) (tx HistoryTx, err error) {
+	if len(hezClient.BootCoordinatorURL) < 10 {
+		err = fmt.Errorf("[GetHistoryTx] Boot Coordinator is not set : %s", hezClient.BootCoordinatorURL)
+		return
+	}
+	req, err := hezClient.BootCoordinatorClient.New().Get("/v1/transactions-history/" + txID.String()).Request()
+	if err != nil {
+		err = fmt.Errorf("[GetHistoryTx] Error creating request: %s", err.Error())
+		return
+	}
+	var failureBody interface{}
+	res, err := hezClient.BootCoordinatorClient.Do(req, &tx, &failureBody)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		err = fmt.Errorf("[GetHistoryTx] TxID: %s - Error: %w", txID.String(), ErrTxNotFound)
+		return
+	}
+	if res != nil && res.StatusCode != http.StatusOK {
+		err = fmt.Errorf("[GetHistoryTx] Error pulling tx from history: %+v - Error: %d", failureBody, res.StatusCode)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("[GetHistoryTx] Error pulling tx from history: %s - Error: %s", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	return
+}
+
+// GetHistoryTxsPage connects to a hermez node and pull a single page of the transactions history. fromItem is the
+// ItemID the page starts at, nil for the first page.
+func GetHistoryTxsPage(hezClient client.HermezClient, filter HistoryTxFilter, fromItem *uint64) (page HistoryTxsAPIResponse, err error) {
+	if len(hezClient.BootCoordinatorURL) < 10 {
+		err = fmt.Errorf("[GetHistoryTxsPage] Boot Coordinator is not set : %s", hezClient.BootCoordinatorURL)
+		return
+	}
+	req, err := hezClient.BootCoordinatorClient.New().Get("/v1/transactions-history?" + filter.query(fromItem)).Request()
+	if err != nil {
+		err = fmt.Errorf("[GetHistoryTxsPage] Error creating request: %s", err.Error())
+		return
+	}
+	var failureBody interface{}
+	res, err := hezClient.BootCoordinatorClient.Do(req, &page, &failureBody)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		// the node answers 404 when no tx matches the filter
+		return HistoryTxsAPIResponse{}, nil
+	}
+	if res != nil && res.StatusCode != http.StatusOK {
+		err = fmt.Errorf("[GetHistoryTxsPage] Error pulling transactions history: %+v - Error: %d", failureBody, res.StatusCode)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("[GetHistoryTxsPage] Error pulling transactions history: %s - Error: %s", hezClient.BootCoordinatorURL, err.Error())
+		return
+	}
+	return
+}
+
+// ForEachHistoryTx goes through all the pages of the transactions history matching the filter and calls fn with each
+// tx, in the filter order. It stops at the first error returned by fn, or when fn returns false.
+func ForEachHistoryTx(hezClient client.HermezClient, filter HistoryTxFilter, fn func(HistoryTx) (bool, error)) (err error) {
+	var fromItem *uint64
+	for {
+		var page HistoryTxsAPIResponse
+		page, err = GetHistoryTxsPage(hezClient, filter, fromItem)
+		if err != nil {
+			err = fmt.Errorf("[ForEachHistoryTx] Error: %w", err)
+			return
+		}
+		for _, tx := range page.Transactions {
+			var next bool
+			if next, err = fn(tx); err != nil || !next {
+				return
+			}
+		}
+		if page.PendingItems < 1 || len(page.Transactions) < 1 {
+			return
+		}
+		fromItem = util.NextFromItem(page.Transactions[len(page.Transactions)-1].ItemID, filter.Order)
+	}
+}
+
+// GetAllHistoryTxs connects to a hermez node and pull every tx of the transactions history matching the filter,
+// going through all the result pages
+func GetAllHistoryTxs(hezClient client.HermezClient, filter HistoryTxFilter) (transactions []HistoryTx, err error) {
+	err = ForEachHistoryTx(hezClient, filter, func(tx HistoryTx) (bool, error) {
+		transactions = append(transactions, tx)
+		return true, nil
+	})
+	if err != nil {
+		err = fmt.Errorf("[GetAllHistoryTxs] Error: %w", err)
+	}
+	return
+}
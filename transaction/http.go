@@ -17,6 +17,10 @@ import (
 
 // ExecuteL2Transaction submits L2 transaction to the current coordinator endpoint
 func ExecuteL2Transaction(hezClient client.HermezClient, apiTx APITx) (apiTxReturn APITx, serverResponse string, err error) {
+	if err = hezClient.CheckSubmit(); err != nil {
+		err = fmt.Errorf("[ExecuteL2Transaction] TxID: %s - Error: %w", apiTx.TxID.String(), err)
+		return
+	}
 	apiTxBody, err := util.MarshallBody(apiTx)
 	if err != nil {
 		err = fmt.Errorf("[ExecuteL2Transaction] Error marshaling HTTP request tx: %+v - Error: %s\n", apiTx, err.Error())
@@ -68,6 +72,10 @@ func ExecuteL2Transaction(hezClient client.HermezClient, apiTx APITx) (apiTxRetu
 
 // SendAtomicTxsGroup submits Atomic transaction to the current coordinator endpoint
 func SendAtomicTxsGroup(hezClient client.HermezClient, atomicTxs hezCommon.AtomicGroup) (serverResponse string, err error) {
+	if err = hezClient.CheckSubmit(); err != nil {
+		err = fmt.Errorf("[SendAtomicTxsGroup] Atomic group: %s - Error: %w", atomicTxs.ID.String(), err)
+		return
+	}
 	apiTxBody, err := util.MarshallBody(atomicTxs)
 	if err != nil {
 		err = fmt.Errorf("[SendAtomicTxsGroup] Error marshaling HTTP request tx: %+v - Error: %s\n", atomicTxs, err.Error())
@@ -157,6 +165,10 @@ func GetTransactionPool(hezClient client.HermezClient, txID hezCommon.TxID) (tra
 	}
 	defer response.Body.Close()
 
+	if response.StatusCode == http.StatusNotFound {
+		err = fmt.Errorf("[GetTransactionPool] TxID: %s - Error: %w", txID.String(), ErrTxNotFound)
+		return
+	}
 	if response.StatusCode < 200 || response.StatusCode > 299 {
 		tempBuf, errResp := io.ReadAll(response.Body)
 		if errResp != nil {
@@ -10,7 +10,7 @@ import (
 
 // L2Transfer perform token or ETH transfer within Hermez network (we say L2 or Layer2)
 func L2Transfer(hezClient client.HermezClient,
-	senderBjjWallet account.BJJWallet,
+	senderBjjWallet account.BJJSigner,
 	receiverAddress string,
 	tokenSymbolToTransfer string,
 	amount *big.Int,
@@ -27,9 +27,10 @@ func L2Transfer(hezClient client.HermezClient,
 
 	err = nil
 
-	senderAccDetails, err := account.GetAccountInfo(hezClient, senderBjjWallet.EthAccount.Address.Hex())
+	senderHezBjjAddress := BjjToString(senderBjjWallet.BJJPublicKey())
+	senderAccDetails, err := account.GetAccountInfo(hezClient, senderHezBjjAddress)
 	if err != nil {
-		err = fmt.Errorf("[L2Transfer] Error obtaining account details. Account: %s - Error: %s\n", senderBjjWallet.HezEthAddress, err.Error())
+		err = fmt.Errorf("[L2Transfer] Error obtaining account details. Account: %s - Error: %s\n", senderHezBjjAddress, err.Error())
 		return
 	}
 
@@ -0,0 +1,118 @@
+package transaction
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dghubble/sling"
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	"github.com/hermeznetwork/hermez-go-sdk/internal/testutil"
+)
+
+// testNode is a stand-in hermez node serving the accounts, the pool and the transactions history. accountsPageSize
+// caps the accounts pages like the node does, 0 for no cap, and accountsStatus makes the accounts query fail.
+type testNode struct {
+	mu               sync.Mutex
+	accounts         []account.Account
+	accountsPageSize int
+	accountsStatus   int
+	pool             map[string]PoolTxAPI
+	history          map[string]HistoryTx
+}
+
+func newTestNode(t *testing.T) (*testNode, client.HermezClient) {
+	node := &testNode{
+		pool:    make(map[string]PoolTxAPI),
+		history: make(map[string]HistoryTx),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(node.serveHTTP))
+	t.Cleanup(srv.Close)
+	hezClient := client.HermezClient{
+		HttpClient:            *srv.Client(),
+		BootCoordinatorURL:    srv.URL,
+		BootCoordinatorClient: sling.New().Base(srv.URL).Client(srv.Client()),
+		EthereumChainID:       5,
+	}
+	hezClient.SetCurrentCoordinator(srv.URL)
+	return node, hezClient
+}
+
+func (n *testNode) setPoolTx(tx PoolTxAPI) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.pool[tx.TxID.String()] = tx
+}
+
+func (n *testNode) removePoolTx(txID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.pool, txID)
+}
+
+func (n *testNode) setHistoryTx(tx HistoryTx) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.history[tx.TxID.String()] = tx
+}
+
+func (n *testNode) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	path := r.URL.Path
+	switch {
+	case path == "/v1/accounts":
+		n.serveAccounts(w, r.URL.Query())
+	case strings.HasPrefix(path, "/v1/accounts/"):
+		idx := strings.TrimPrefix(path, "/v1/accounts/")
+		for _, acc := range n.accounts {
+			if acc.AccountIndex == idx {
+				testutil.WriteJSON(w, acc)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case strings.HasPrefix(path, "/v1/transactions-pool/"):
+		if tx, ok := n.pool[strings.TrimPrefix(path, "/v1/transactions-pool/")]; ok {
+			testutil.WriteJSON(w, tx)
+			return
+		}
+		http.NotFound(w, r)
+	case strings.HasPrefix(path, "/v1/transactions-history/"):
+		if tx, ok := n.history[strings.TrimPrefix(path, "/v1/transactions-history/")]; ok {
+			testutil.WriteJSON(w, tx)
+			return
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (n *testNode) serveAccounts(w http.ResponseWriter, query url.Values) {
+	if n.accountsStatus != 0 {
+		w.WriteHeader(n.accountsStatus)
+		return
+	}
+	fromItem, _ := strconv.Atoi(query.Get("fromItem"))
+	var page account.AccountAPIResponse
+	for _, acc := range n.accounts {
+		if acc.ItemID < fromItem {
+			continue
+		}
+		if (query.Get("hezEthereumAddress") != "" && strings.EqualFold(acc.HezEthereumAddress, query.Get("hezEthereumAddress"))) ||
+			(query.Get("BJJ") != "" && acc.BJJAddress == query.Get("BJJ")) {
+			if n.accountsPageSize > 0 && len(page.Accounts) == n.accountsPageSize {
+				page.PendingItems++
+				continue
+			}
+			page.Accounts = append(page.Accounts, acc)
+		}
+	}
+	testutil.WriteJSON(w, page)
+}
@@ -0,0 +1,481 @@
+package transaction
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/common/apitypes"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+// OfflineFileVersion is the version of the OfflineFile JSON format
+const OfflineFileVersion = 1
+
+var (
+	// ErrOfflineFileVersion is returned when loading an OfflineFile written with another format version
+	ErrOfflineFileVersion = errors.New("unsupported offline file version")
+	// ErrOfflineTxInvalid is returned when an OfflineTx fields are inconsistent, e.g. the TxID doesn't match them
+	ErrOfflineTxInvalid = errors.New("invalid offline transaction")
+	// ErrOfflineTxNotSigned is returned when broadcasting an OfflineTx without signature
+	ErrOfflineTxNotSigned = errors.New("offline transaction is not signed")
+	// ErrOfflineTxInvalidSignature is returned when the OfflineTx signature wasn't produced by its sender BJJ key
+	ErrOfflineTxInvalidSignature = errors.New("invalid offline transaction signature")
+	// ErrOfflineChainIDMismatch is returned when broadcasting an OfflineFile prepared for another chain
+	ErrOfflineChainIDMismatch = errors.New("offline file was prepared for another chain ID")
+)
+
+// OfflineTx is a PoolL2Tx with the idx, nonce, fee, token and Rq* fields already resolved, so it can be validated and
+// signed on a machine without network access. Signature is empty until the tx is signed.
+type OfflineTx struct {
+	TxID        hezCommon.TxID        `json:"id"`
+	Type        hezCommon.TxType      `json:"type"`
+	FromBJJ     string                `json:"fromBjj"`
+	FromIdx     hezCommon.Idx         `json:"fromIdx"`
+	ToIdx       hezCommon.Idx         `json:"toIdx"`
+	ToEthAddr   ethCommon.Address     `json:"toEthAddr"`
+	ToBJJ       babyjub.PublicKeyComp `json:"toBjj"`
+	TokenID     hezCommon.TokenID     `json:"tokenId"`
+	TokenSymbol string                `json:"tokenSymbol"`
+	Amount      string                `json:"amount"`
+	Fee         hezCommon.FeeSelector `json:"fee"`
+	Nonce       hezCommon.Nonce       `json:"nonce"`
+	MaxNumBatch uint32                `json:"maxNumBatch"`
+	RqOffset    uint8                 `json:"rqOffset"`
+	RqFromIdx   hezCommon.Idx         `json:"rqFromIdx"`
+	RqToIdx     hezCommon.Idx         `json:"rqToIdx"`
+	RqToEthAddr ethCommon.Address     `json:"rqToEthAddr"`
+	RqToBJJ     babyjub.PublicKeyComp `json:"rqToBjj"`
+	RqTokenID   hezCommon.TokenID     `json:"rqTokenId"`
+	RqAmount    string                `json:"rqAmount"`
+	RqFee       hezCommon.FeeSelector `json:"rqFee"`
+	RqNonce     hezCommon.Nonce       `json:"rqNonce"`
+	Signature   babyjub.SignatureComp `json:"signature"`
+}
+
+// OfflineAtomicGroup is an atomic group of OfflineTxs. The ID only depends on the TxIDs, so it is known before signing
+type OfflineAtomicGroup struct {
+	ID  hezCommon.AtomicGroupID `json:"atomicGroupId"`
+	Txs []OfflineTx             `json:"txs"`
+}
+
+// OfflineFile is the versioned file moved between the online machine, that prepares and broadcasts the transactions,
+// and the offline machine, that signs them
+type OfflineFile struct {
+	Version      int                  `json:"version"`
+	ChainID      int                  `json:"chainId"`
+	Txs          []OfflineTx          `json:"txs,omitempty"`
+	AtomicGroups []OfflineAtomicGroup `json:"atomicGroups,omitempty"`
+}
+
+// OfflineBroadcastResult is the outcome of each tx or atomic group sent by BroadcastOfflineFile
+type OfflineBroadcastResult struct {
+	TxID           hezCommon.TxID
+	AtomicGroupID  hezCommon.AtomicGroupID
+	ServerResponse string
+}
+
+// NewOfflineFile builds an OfflineFile for the chain ID with the prepared txs and atomic groups
+func NewOfflineFile(chainID int, txs []OfflineTx, atomicGroups []OfflineAtomicGroup) OfflineFile {
+	return OfflineFile{
+		Version:      OfflineFileVersion,
+		ChainID:      chainID,
+		Txs:          txs,
+		AtomicGroups: atomicGroups,
+	}
+}
+
+// PrepareL2Transfer resolves the accounts, nonce and token of a transfer like L2Transfer does, without signing it.
+// The senderBjjWallet only needs the public key, see account.NewWatchOnlyBJJSigner
+func PrepareL2Transfer(hezClient client.HermezClient,
+	senderBjjWallet account.BJJSigner,
+	receiverAddress string,
+	tokenSymbolToTransfer string,
+	amount *big.Int,
+	feeRangeSelectedID int) (offlineTx OfflineTx, err error) {
+
+	senderHezBjjAddress := BjjToString(senderBjjWallet.BJJPublicKey())
+	senderAccDetails, err := account.GetAccountInfo(hezClient, senderHezBjjAddress)
+	if err != nil {
+		err = fmt.Errorf("[PrepareL2Transfer] Error obtaining account details. Account: %s - Error: %s\n", senderHezBjjAddress, err.Error())
+		return
+	}
+	receiverAccDetails, err := account.GetAccountInfo(hezClient, receiverAddress)
+	if err != nil {
+		err = fmt.Errorf("[PrepareL2Transfer] Error obtaining account details. Account: %s - Error: %s\n", receiverAddress, err.Error())
+		return
+	}
+
+	tx, token, err := newTransferPoolL2Tx(tokenSymbolToTransfer, senderAccDetails, receiverAccDetails, senderHezBjjAddress, amount, feeRangeSelectedID)
+	if err != nil {
+		err = fmt.Errorf("[PrepareL2Transfer] Error building tx. Error: %s\n", err.Error())
+		return
+	}
+	tx.TokenSymbol = token.Symbol
+	if _, err = hezCommon.NewPoolL2Tx(tx); err != nil {
+		err = fmt.Errorf("[PrepareL2Transfer] Error building tx. Error: %s\n", err.Error())
+		return
+	}
+	return NewOfflineTx(*tx, senderHezBjjAddress), nil
+}
+
+// PrepareAtomicGroup resolves the accounts, nonces, tokens and links of the atomic group like AtomicTransfer does,
+// without signing it. The SenderBjjWallet of the items only need the public key, see account.NewWatchOnlyBJJSigner
+func PrepareAtomicGroup(hezClient client.HermezClient, txs []AtomicTxItem) (offlineGroup OfflineAtomicGroup, err error) {
+	atomicGroup := hezCommon.AtomicGroup{}
+	atomicGroup.Txs, err = CreateFullTxs(hezClient, txs)
+	if err != nil {
+		err = fmt.Errorf("[PrepareAtomicGroup] Error generating PoolL2Tx. Error: %s\n", err.Error())
+		return
+	}
+	atomicGroup = SetAtomicGroupID(atomicGroup)
+	if err = ValidateAtomicGroup(atomicGroup, DefaultMaxAtomicGroupSize); err != nil {
+		err = fmt.Errorf("[PrepareAtomicGroup] Invalid atomic group - Error: %w", err)
+		return
+	}
+
+	offlineGroup.ID = atomicGroup.ID
+	for i := range atomicGroup.Txs {
+		offlineGroup.Txs = append(offlineGroup.Txs, NewOfflineTx(atomicGroup.Txs[i], BjjToString(txs[i].SenderBjjWallet.BJJPublicKey())))
+	}
+	return
+}
+
+// NewOfflineTx converts a PoolL2Tx, with TxID and Type already set, to an OfflineTx sent by the hez BJJ address
+func NewOfflineTx(tx hezCommon.PoolL2Tx, fromHezBjjAddress string) OfflineTx {
+	offlineTx := OfflineTx{
+		TxID:        tx.TxID,
+		Type:        tx.Type,
+		FromBJJ:     fromHezBjjAddress,
+		FromIdx:     tx.FromIdx,
+		ToIdx:       tx.ToIdx,
+		ToEthAddr:   tx.ToEthAddr,
+		ToBJJ:       tx.ToBJJ,
+		TokenID:     tx.TokenID,
+		TokenSymbol: tx.TokenSymbol,
+		Amount:      "0",
+		Fee:         tx.Fee,
+		Nonce:       tx.Nonce,
+		MaxNumBatch: tx.MaxNumBatch,
+		RqOffset:    tx.RqOffset,
+		RqFromIdx:   tx.RqFromIdx,
+		RqToIdx:     tx.RqToIdx,
+		RqToEthAddr: tx.RqToEthAddr,
+		RqToBJJ:     tx.RqToBJJ,
+		RqTokenID:   tx.RqTokenID,
+		RqFee:       tx.RqFee,
+		RqNonce:     tx.RqNonce,
+		Signature:   tx.Signature,
+	}
+	if tx.Amount != nil {
+		offlineTx.Amount = tx.Amount.String()
+	}
+	if tx.RqAmount != nil {
+		offlineTx.RqAmount = tx.RqAmount.String()
+	}
+	return offlineTx
+}
+
+// PoolL2Tx converts the OfflineTx back to a PoolL2Tx
+func (t OfflineTx) PoolL2Tx() (tx hezCommon.PoolL2Tx, err error) {
+	amount, ok := new(big.Int).SetString(t.Amount, 10)
+	if !ok {
+		err = fmt.Errorf("[OfflineTx][PoolL2Tx] Invalid amount: %s - Error: %w", t.Amount, ErrOfflineTxInvalid)
+		return
+	}
+	var rqAmount *big.Int
+	if len(t.RqAmount) > 0 {
+		if rqAmount, ok = new(big.Int).SetString(t.RqAmount, 10); !ok {
+			err = fmt.Errorf("[OfflineTx][PoolL2Tx] Invalid rq amount: %s - Error: %w", t.RqAmount, ErrOfflineTxInvalid)
+			return
+		}
+	}
+	tx = hezCommon.PoolL2Tx{
+		TxID:        t.TxID,
+		Type:        t.Type,
+		FromIdx:     t.FromIdx,
+		ToIdx:       t.ToIdx,
+		ToEthAddr:   t.ToEthAddr,
+		ToBJJ:       t.ToBJJ,
+		TokenID:     t.TokenID,
+		TokenSymbol: t.TokenSymbol,
+		Amount:      amount,
+		Fee:         t.Fee,
+		Nonce:       t.Nonce,
+		MaxNumBatch: t.MaxNumBatch,
+		RqOffset:    t.RqOffset,
+		RqFromIdx:   t.RqFromIdx,
+		RqToIdx:     t.RqToIdx,
+		RqToEthAddr: t.RqToEthAddr,
+		RqToBJJ:     t.RqToBJJ,
+		RqTokenID:   t.RqTokenID,
+		RqAmount:    rqAmount,
+		RqFee:       t.RqFee,
+		RqNonce:     t.RqNonce,
+		Signature:   t.Signature,
+	}
+	return
+}
+
+// IsSigned returns true once the OfflineTx has a signature
+func (t OfflineTx) IsSigned() bool {
+	return t.Signature != babyjub.SignatureComp{}
+}
+
+// String describes the OfflineTx, to be reviewed before signing it
+func (t OfflineTx) String() string {
+	to := IdxToHez(t.ToIdx, t.TokenSymbol)
+	switch {
+	case t.Type == hezCommon.TxTypeExit:
+		to = "exit"
+	case t.ToIdx == 0 && t.ToBJJ != hezCommon.EmptyBJJComp:
+		to = BjjToString(t.ToBJJ)
+	case t.ToIdx == 0:
+		to = ethAddrToHez(t.ToEthAddr)
+	}
+	description := fmt.Sprintf("%s %s: %s %s from %s (%s) to %s - fee: %d - nonce: %d",
+		t.TxID.String(), t.Type, t.Amount, t.TokenSymbol, IdxToHez(t.FromIdx, t.TokenSymbol), t.FromBJJ, to, t.Fee, t.Nonce)
+	if t.RqFromIdx != 0 {
+		description += fmt.Sprintf(" - requires tx at offset %d: %s of token ID %d from idx %d - nonce: %d", t.RqOffset, t.RqAmount, t.RqTokenID, t.RqFromIdx, t.RqNonce)
+	}
+	if !t.IsSigned() {
+		description += " - not signed"
+	}
+	return description
+}
+
+// Validate checks that the TxID and Type match the tx fields, the amount can be encoded and, if the tx is signed,
+// that the signature was produced by the sender BJJ key for the chain ID
+func (t OfflineTx) Validate(chainID int) (err error) {
+	tx, err := t.PoolL2Tx()
+	if err != nil {
+		return
+	}
+	if _, err = hezCommon.NewPoolL2Tx(&tx); err != nil {
+		err = fmt.Errorf("[OfflineTx][Validate] TxID: %s - %s - Error: %w", t.TxID.String(), err.Error(), ErrOfflineTxInvalid)
+		return
+	}
+	if _, err = hezCommon.NewFloat40(tx.Amount); err != nil {
+		err = fmt.Errorf("[OfflineTx][Validate] TxID: %s - Invalid amount: %s - Error: %w", t.TxID.String(), t.Amount, ErrOfflineTxInvalid)
+		return
+	}
+	fromBJJ, err := apitypes.HezBJJ(t.FromBJJ).ToBJJ()
+	if err != nil {
+		err = fmt.Errorf("[OfflineTx][Validate] TxID: %s - Invalid sender BJJ: %s - Error: %w", t.TxID.String(), t.FromBJJ, ErrOfflineTxInvalid)
+		return
+	}
+	if t.IsSigned() && !tx.VerifySignature(uint16(chainID), fromBJJ) {
+		err = fmt.Errorf("[OfflineTx][Validate] TxID: %s - Error: %w", t.TxID.String(), ErrOfflineTxInvalidSignature)
+		return
+	}
+	return nil
+}
+
+// Validate checks every tx of the group and the group itself with ValidateAtomicGroup
+func (g OfflineAtomicGroup) Validate(chainID int) (err error) {
+	atomicGroup := hezCommon.AtomicGroup{ID: g.ID, Txs: make([]hezCommon.PoolL2Tx, len(g.Txs))}
+	for i := range g.Txs {
+		if err = g.Txs[i].Validate(chainID); err != nil {
+			return
+		}
+		if atomicGroup.Txs[i], err = g.Txs[i].PoolL2Tx(); err != nil {
+			return
+		}
+	}
+	if err = ValidateAtomicGroup(atomicGroup, 0); err != nil {
+		err = fmt.Errorf("[OfflineAtomicGroup][Validate] Atomic group: %s - Error: %w", g.ID.String(), err)
+		return
+	}
+	return nil
+}
+
+// Validate checks the format version and every tx and atomic group of the file
+func (f OfflineFile) Validate() (err error) {
+	if f.Version != OfflineFileVersion {
+		err = fmt.Errorf("[OfflineFile][Validate] Version: %d - Error: %w", f.Version, ErrOfflineFileVersion)
+		return
+	}
+	if f.ChainID > 65535 || f.ChainID < 1 {
+		err = fmt.Errorf("[OfflineFile][Validate] Invalid chainID: %d", f.ChainID)
+		return
+	}
+	for _, tx := range f.Txs {
+		if err = tx.Validate(f.ChainID); err != nil {
+			return
+		}
+	}
+	for _, atomicGroup := range f.AtomicGroups {
+		if err = atomicGroup.Validate(f.ChainID); err != nil {
+			return
+		}
+	}
+	return nil
+}
+
+// String describes every tx and atomic group of the file, to be reviewed before signing them
+func (f OfflineFile) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Offline file version %d - chain ID %d\n", f.Version, f.ChainID)
+	for _, tx := range f.Txs {
+		fmt.Fprintf(&sb, "%s\n", tx)
+	}
+	for _, atomicGroup := range f.AtomicGroups {
+		fmt.Fprintf(&sb, "Atomic group %s\n", atomicGroup.ID.String())
+		for _, tx := range atomicGroup.Txs {
+			fmt.Fprintf(&sb, "  %s\n", tx)
+		}
+	}
+	return sb.String()
+}
+
+// UnmarshalOfflineFile parses an OfflineFile and checks its format version
+func UnmarshalOfflineFile(data []byte) (offlineFile OfflineFile, err error) {
+	if err = json.Unmarshal(data, &offlineFile); err != nil {
+		err = fmt.Errorf("[UnmarshalOfflineFile] Error unmarshaling offline file - Error: %s", err.Error())
+		return
+	}
+	if offlineFile.Version != OfflineFileVersion {
+		err = fmt.Errorf("[UnmarshalOfflineFile] Version: %d - Error: %w", offlineFile.Version, ErrOfflineFileVersion)
+		return OfflineFile{}, err
+	}
+	return
+}
+
+// LoadOfflineFile reads an OfflineFile from path
+func LoadOfflineFile(path string) (offlineFile OfflineFile, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		err = fmt.Errorf("[LoadOfflineFile] Error reading %s - Error: %s", path, err.Error())
+		return
+	}
+	return UnmarshalOfflineFile(data)
+}
+
+// SaveOfflineFile writes the OfflineFile to path
+func SaveOfflineFile(path string, offlineFile OfflineFile) (err error) {
+	data, err := json.MarshalIndent(offlineFile, "", "  ")
+	if err != nil {
+		err = fmt.Errorf("[SaveOfflineFile] Error marshaling offline file - Error: %s", err.Error())
+		return
+	}
+	if err = ioutil.WriteFile(path, data, 0600); err != nil {
+		err = fmt.Errorf("[SaveOfflineFile] Error writing %s - Error: %s", path, err.Error())
+		return
+	}
+	return
+}
+
+// SignOfflineFile validates the file and signs every unsigned tx sent by one of the signers. Txs of other senders are
+// left unsigned, so the file can be passed to the next signer. It returns how many txs were signed.
+func SignOfflineFile(offlineFile *OfflineFile, signers ...account.BJJSigner) (signed int, err error) {
+	if err = offlineFile.Validate(); err != nil {
+		err = fmt.Errorf("[SignOfflineFile] Error validating offline file - Error: %w", err)
+		return
+	}
+	signersByBJJ := make(map[string]account.BJJSigner, len(signers))
+	for _, signer := range signers {
+		signersByBJJ[BjjToString(signer.BJJPublicKey())] = signer
+	}
+
+	txs := make([]*OfflineTx, 0, len(offlineFile.Txs))
+	for i := range offlineFile.Txs {
+		txs = append(txs, &offlineFile.Txs[i])
+	}
+	for i := range offlineFile.AtomicGroups {
+		for j := range offlineFile.AtomicGroups[i].Txs {
+			txs = append(txs, &offlineFile.AtomicGroups[i].Txs[j])
+		}
+	}
+	for _, offlineTx := range txs {
+		signer, ok := signersByBJJ[offlineTx.FromBJJ]
+		if !ok || offlineTx.IsSigned() {
+			continue
+		}
+		if err = offlineTx.sign(offlineFile.ChainID, signer); err != nil {
+			err = fmt.Errorf("[SignOfflineFile] Error signing tx. TxID: %s - Error: %w", offlineTx.TxID.String(), err)
+			return
+		}
+		signed++
+	}
+	return
+}
+
+// sign signs the OfflineTx hash for the chain ID with the signer
+func (t *OfflineTx) sign(chainID int, signer account.BJJSigner) error {
+	tx, err := t.PoolL2Tx()
+	if err != nil {
+		return err
+	}
+	txHash, err := tx.HashToSign(uint16(chainID))
+	if err != nil {
+		return err
+	}
+	signature, err := signer.SignPoseidon(txHash)
+	if err != nil {
+		return err
+	}
+	t.Signature = signature.Compress()
+	return nil
+}
+
+// BroadcastOfflineFile validates the signed file and sends its txs with ExecuteL2Transaction and its atomic groups
+// with SendAtomicTxsGroup. Every tx must be signed. It stops at the first error, results holds what was sent before.
+func BroadcastOfflineFile(hezClient client.HermezClient, offlineFile OfflineFile) (results []OfflineBroadcastResult, err error) {
+	if offlineFile.ChainID != hezClient.EthereumChainID {
+		err = fmt.Errorf("[BroadcastOfflineFile] File chain ID: %d - Client chain ID: %d - Error: %w", offlineFile.ChainID, hezClient.EthereumChainID, ErrOfflineChainIDMismatch)
+		return
+	}
+	if err = offlineFile.Validate(); err != nil {
+		err = fmt.Errorf("[BroadcastOfflineFile] Error validating offline file - Error: %w", err)
+		return
+	}
+	for _, offlineTx := range offlineFile.Txs {
+		if !offlineTx.IsSigned() {
+			err = fmt.Errorf("[BroadcastOfflineFile] TxID: %s - Error: %w", offlineTx.TxID.String(), ErrOfflineTxNotSigned)
+			return
+		}
+	}
+	for _, atomicGroup := range offlineFile.AtomicGroups {
+		for _, offlineTx := range atomicGroup.Txs {
+			if !offlineTx.IsSigned() {
+				err = fmt.Errorf("[BroadcastOfflineFile] Atomic group: %s - TxID: %s - Error: %w", atomicGroup.ID.String(), offlineTx.TxID.String(), ErrOfflineTxNotSigned)
+				return
+			}
+		}
+	}
+
+	for _, offlineTx := range offlineFile.Txs {
+		tx, _ := offlineTx.PoolL2Tx()
+		apiTx := NewHermezAPITxRequest(&tx, hezCommon.Token{TokenID: tx.TokenID, Symbol: tx.TokenSymbol})
+		var serverResponse string
+		_, serverResponse, err = ExecuteL2Transaction(hezClient, apiTx)
+		if err != nil {
+			err = fmt.Errorf("[BroadcastOfflineFile] Error submiting tx. TxID: %s - Error: %s\n", offlineTx.TxID.String(), err.Error())
+			return
+		}
+		results = append(results, OfflineBroadcastResult{TxID: offlineTx.TxID, ServerResponse: serverResponse})
+	}
+	for _, offlineGroup := range offlineFile.AtomicGroups {
+		atomicGroup := hezCommon.AtomicGroup{ID: offlineGroup.ID}
+		for _, offlineTx := range offlineGroup.Txs {
+			tx, _ := offlineTx.PoolL2Tx()
+			tx.AtomicGroupID = offlineGroup.ID
+			atomicGroup.Txs = append(atomicGroup.Txs, tx)
+		}
+		var serverResponse string
+		serverResponse, err = SendAtomicTxsGroup(hezClient, atomicGroup)
+		if err != nil {
+			err = fmt.Errorf("[BroadcastOfflineFile] Error sending atomic group. ID: %s - Error: %s\n", offlineGroup.ID.String(), err.Error())
+			return
+		}
+		results = append(results, OfflineBroadcastResult{AtomicGroupID: offlineGroup.ID, ServerResponse: serverResponse})
+	}
+	return
+}
@@ -0,0 +1,175 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+)
+
+// Errors reported by the coordinators for the txs in the pool. The coordinator sets the error code while the tx is
+// still pending, to tell why it wasn't selected in the last batch, and invalidates the txs whose nonce is already
+// used. Match them with errors.Is on the error returned by PoolTxAPI.PoolError, TxOutcome.Err or AtomicLegStatus.Err
+var (
+	// ErrPoolTxExitAmount is error code 1, exits of amount 0 are not accepted
+	ErrPoolTxExitAmount = errors.New("exit with amount 0")
+	// ErrPoolTxMaxNumBatch is error code 2, the batch limit of the tx was exceeded
+	ErrPoolTxMaxNumBatch = errors.New("max num batch exceeded")
+	// ErrPoolTxNotEnoughBalance is error code 11, the sender doesn't have enough balance yet
+	ErrPoolTxNotEnoughBalance = errors.New("sender has not enough balance")
+	// ErrPoolTxNonceNotCurrent is error code 12, the txs with the previous nonces of the sender weren't forged yet
+	ErrPoolTxNonceNotCurrent = errors.New("tx nonce is not the current account nonce")
+	// ErrPoolTxNoSpaceL1Coordinator is error code 13, the tx needs an account created by the coordinator and the
+	// batch had no space for it
+	ErrPoolTxNoSpaceL1Coordinator = errors.New("not enough space for the L1 coordinator tx")
+	// ErrPoolTxDiscardedToEthAddrBJJ is error code 14, the TransferToEthAddr or TransferToBJJ couldn't be processed
+	ErrPoolTxDiscardedToEthAddrBJJ = errors.New("tx discarded processing the ToEthAddr/ToBJJ recipient")
+	// ErrPoolTxToIdxNotFound is error code 15, the receiver account doesn't exist
+	ErrPoolTxToIdxNotFound = errors.New("receiver account idx not found")
+	// ErrPoolTxDiscardedL2Tx is error code 16, the tx failed when processed in the batch
+	ErrPoolTxDiscardedL2Tx = errors.New("tx discarded processing the L2 tx")
+	// ErrPoolTxNoAvailableSlots is error code 17, the batch had no space left for L2 txs
+	ErrPoolTxNoAvailableSlots = errors.New("no available slots for L2 txs")
+	// ErrPoolTxInvalidAtomicGroup is error code 18, the atomic group of the tx has missing or wrong txs
+	ErrPoolTxInvalidAtomicGroup = errors.New("invalid atomic group")
+	// ErrPoolTxNonceTooLow is reported for invalid txs whose nonce is smaller than the account nonce
+	ErrPoolTxNonceTooLow = errors.New("nonce is smaller than the account nonce")
+	// ErrPoolTxInvalid is reported for invalid txs without a known reason
+	ErrPoolTxInvalid = errors.New("tx invalidated by the coordinator")
+	// ErrPoolTxUnknownError is reported for error codes not in the catalog
+	ErrPoolTxUnknownError = errors.New("unknown pool tx error")
+)
+
+// nonceTooLowInfo is the info the coordinator sets on the txs it invalidates because their nonce is already used
+const nonceTooLowInfo = "Nonce is smaller than account nonce"
+
+// PoolTxErrorInfo describes a pool error code. Retryable is true when the same tx can still be forged later, e.g.
+// once the sender has balance or there is space in a batch. Otherwise a new tx is needed.
+type PoolTxErrorInfo struct {
+	Code        int
+	Type        string
+	Description string
+	Retryable   bool
+	Err         error
+}
+
+var poolTxErrorCatalog = map[int]PoolTxErrorInfo{
+	1:  {Code: 1, Type: "ErrExit0Amount", Description: "Exits with amount 0 are not accepted", Retryable: false, Err: ErrPoolTxExitAmount},
+	2:  {Code: 2, Type: "ErrUnsupportedMaxNumBatch", Description: "The MaxNumBatch of the tx was exceeded", Retryable: false, Err: ErrPoolTxMaxNumBatch},
+	11: {Code: 11, Type: "ErrSenderNotEnoughBalance", Description: "The sender doesn't have enough balance for the amount and fee", Retryable: true, Err: ErrPoolTxNotEnoughBalance},
+	12: {Code: 12, Type: "ErrNoCurrentNonce", Description: "The txs with the previous nonces of the sender are not forged yet", Retryable: true, Err: ErrPoolTxNonceNotCurrent},
+	13: {Code: 13, Type: "ErrNotEnoughSpaceL1Coordinator", Description: "The tx needs an account created by the coordinator and the batch had no space for it", Retryable: true, Err: ErrPoolTxNoSpaceL1Coordinator},
+	14: {Code: 14, Type: "ErrTxDiscartedInProcessTxToEthAddrBJJ", Description: "The recipient of the TransferToEthAddr or TransferToBJJ has no account for the token", Retryable: true, Err: ErrPoolTxDiscardedToEthAddrBJJ},
+	15: {Code: 15, Type: "ErrToIdxNotFound", Description: "The receiver account doesn't exist", Retryable: false, Err: ErrPoolTxToIdxNotFound},
+	16: {Code: 16, Type: "ErrTxDiscartedInProcessL2Tx", Description: "The tx failed when it was processed in the batch", Retryable: true, Err: ErrPoolTxDiscardedL2Tx},
+	17: {Code: 17, Type: "ErrNoAvailableSlots", Description: "The batch had no space left for L2 txs", Retryable: true, Err: ErrPoolTxNoAvailableSlots},
+	18: {Code: 18, Type: "ErrInvalidAtomicGroup", Description: "The atomic group has missing txs or a wrong requested tx", Retryable: false, Err: ErrPoolTxInvalidAtomicGroup},
+}
+
+// LookupPoolTxError returns the catalog entry of a pool error code
+func LookupPoolTxError(code int) (info PoolTxErrorInfo, ok bool) {
+	info, ok = poolTxErrorCatalog[code]
+	return
+}
+
+// PoolTxError is the error of a tx in the pool. It wraps one of the ErrPoolTx* errors.
+type PoolTxError struct {
+	State     hezCommon.PoolL2TxState
+	Code      int
+	Type      string
+	Info      string
+	Retryable bool
+	Err       error
+}
+
+func (e *PoolTxError) Error() string {
+	description := fmt.Sprintf("pool tx %s: %s", e.State, e.Err.Error())
+	if e.Code != 0 {
+		description += fmt.Sprintf(" - code: %d %s", e.Code, e.Type)
+	}
+	if len(e.Info) > 0 {
+		description += " - info: " + strings.TrimSpace(e.Info)
+	}
+	return description
+}
+
+// Unwrap returns the ErrPoolTx* error describing the problem
+func (e *PoolTxError) Unwrap() error {
+	return e.Err
+}
+
+// NewPoolTxError builds the error of a tx from its pool state and error fields. It returns nil when the tx is not
+// invalid and has no error code. An invalid tx is never retryable, even if its last error code was.
+func NewPoolTxError(state hezCommon.PoolL2TxState, errorCode int, errorType string, info string) error {
+	if errorCode == 0 && state != hezCommon.PoolL2TxStateInvalid {
+		return nil
+	}
+	poolErr := &PoolTxError{State: state, Code: errorCode, Type: errorType, Info: info}
+	switch {
+	case errorCode != 0:
+		catalogEntry, ok := LookupPoolTxError(errorCode)
+		if !ok {
+			poolErr.Err = ErrPoolTxUnknownError
+			break
+		}
+		poolErr.Err = catalogEntry.Err
+		poolErr.Retryable = catalogEntry.Retryable
+		if len(poolErr.Type) == 0 {
+			poolErr.Type = catalogEntry.Type
+		}
+	case strings.HasPrefix(info, nonceTooLowInfo):
+		poolErr.Err = ErrPoolTxNonceTooLow
+	default:
+		poolErr.Err = ErrPoolTxInvalid
+	}
+	if state == hezCommon.PoolL2TxStateInvalid {
+		poolErr.Retryable = false
+	}
+	return poolErr
+}
+
+// IsRetryablePoolTxError returns true when err holds a PoolTxError the tx can still recover from, so it's worth
+// waiting instead of sending a new tx
+func IsRetryablePoolTxError(err error) bool {
+	var poolErr *PoolTxError
+	return errors.As(err, &poolErr) && poolErr.Retryable
+}
+
+// IsPending returns true while the tx waits in the pool to be selected
+func (tx PoolTxAPI) IsPending() bool {
+	return tx.State == hezCommon.PoolL2TxStatePending
+}
+
+// IsForging returns true when the tx was selected in a batch that is not forged yet
+func (tx PoolTxAPI) IsForging() bool {
+	return tx.State == hezCommon.PoolL2TxStateForging
+}
+
+// IsForged returns true once the tx is forged
+func (tx PoolTxAPI) IsForged() bool {
+	return tx.State == hezCommon.PoolL2TxStateForged
+}
+
+// IsInvalid returns true when the coordinator invalidated the tx, it will never be forged
+func (tx PoolTxAPI) IsInvalid() bool {
+	return tx.State == hezCommon.PoolL2TxStateInvalid
+}
+
+// PoolError returns the PoolTxError of the tx, nil when it has no error
+func (tx PoolTxAPI) PoolError() error {
+	return NewPoolTxError(tx.State, tx.ErrorCode, tx.ErrorType, tx.Info)
+}
+
+// Err returns the PoolTxError of an invalid tx, nil otherwise
+func (o TxOutcome) Err() error {
+	if o.State != TxOutcomeInvalid {
+		return nil
+	}
+	return NewPoolTxError(hezCommon.PoolL2TxStateInvalid, o.ErrorCode, o.ErrorType, o.Info)
+}
+
+// Err returns the PoolTxError of the leg, nil when it has no error
+func (l AtomicLegStatus) Err() error {
+	return NewPoolTxError(l.State, l.ErrorCode, l.ErrorType, l.Info)
+}
@@ -0,0 +1,184 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+)
+
+// ErrRecipientCannotReceive is returned when a TransferToEthAddr recipient has neither an account for the token nor
+// an account creation authorization, so the coordinator would never forge the transaction
+var ErrRecipientCannotReceive = errors.New("recipient has no account for the token and no account creation authorization")
+
+// RecipientCheckPolicy defines what to do when a TransferToEthAddr recipient can't receive funds
+type RecipientCheckPolicy int
+
+const (
+	// RecipientCheckNone sends the transactions without checking the recipients
+	RecipientCheckNone RecipientCheckPolicy = iota
+	// RecipientCheckFailFast checks every recipient before sending and stops at the first one that can't receive
+	RecipientCheckFailFast
+	// RecipientCheckSkip checks every recipient and only sends to the ones that can receive
+	RecipientCheckSkip
+)
+
+// RecipientStatus tells if an Ethereum address is able to receive a TransferToEthAddr of a token
+type RecipientStatus struct {
+	EthAddress             ethCommon.Address
+	TokenID                hezCommon.TokenID
+	AccountIdx             hezCommon.Idx
+	HasAccount             bool
+	HasAccountCreationAuth bool
+}
+
+// CanReceive returns true if the coordinator is able to forge a TransferToEthAddr to this recipient
+func (r RecipientStatus) CanReceive() bool {
+	return r.HasAccount || r.HasAccountCreationAuth
+}
+
+// EthAddrTransferResult is the outcome of each transfer sent by L2TransfersToEthAddr
+type EthAddrTransferResult struct {
+	Receiver       TxReceiverMetadata
+	Recipient      RecipientStatus
+	Skipped        bool
+	APITx          APITx
+	ServerResponse string
+}
+
+// CheckEthAddrRecipient queries the recipient accounts and the account creation authorizations to find out if
+// a TransferToEthAddr of the token to that Ethereum address can be forged
+func CheckEthAddrRecipient(hezClient client.HermezClient, toEthAddress string, token hezCommon.Token) (recipient RecipientStatus, err error) {
+	if !ethCommon.IsHexAddress(toEthAddress) {
+		err = fmt.Errorf("[CheckEthAddrRecipient] Invalid Ethereum address: %s", toEthAddress)
+		return
+	}
+	recipient.EthAddress = ethCommon.HexToAddress(toEthAddress)
+	recipient.TokenID = token.TokenID
+
+	accounts, err := account.GetAllAccountsInfo(hezClient, recipient.EthAddress.Hex())
+	if err != nil {
+		err = fmt.Errorf("[CheckEthAddrRecipient] Error obtaining account details. Account: %s - Error: %w", toEthAddress, err)
+		return
+	}
+	for _, innerAccount := range accounts {
+		if hezCommon.TokenID(innerAccount.Token.ID) != token.TokenID {
+			continue
+		}
+		var strHezIdx hezCommon.StrHezIdx
+		if err = strHezIdx.UnmarshalText([]byte(innerAccount.AccountIndex)); err != nil {
+			err = fmt.Errorf("[CheckEthAddrRecipient] Error parsing account idx. Account Index: %s - Error: %s\n", innerAccount.AccountIndex, err.Error())
+			return
+		}
+		recipient.AccountIdx = strHezIdx.Idx
+		recipient.HasAccount = true
+		return
+	}
+
+	recipient.HasAccountCreationAuth, err = account.HasAccountCreationAuth(hezClient, recipient.EthAddress.Hex())
+	if err != nil {
+		err = fmt.Errorf("[CheckEthAddrRecipient] Error obtaining account creation authorization. Account: %s - Error: %s\n", toEthAddress, err.Error())
+		return
+	}
+	return
+}
+
+// L2TransferToEthAddr signs and sends a TransferToEthAddr. Unless the policy is RecipientCheckNone the recipient is
+// checked first and ErrRecipientCannotReceive is returned without sending when it can't receive the funds.
+func L2TransferToEthAddr(hezClient client.HermezClient,
+	fromBjjWallet account.BJJSigner,
+	fromIdx uint64,
+	toEthAddress string,
+	amount *big.Int,
+	feeSelector hezCommon.FeeSelector,
+	token hezCommon.Token,
+	nonce int,
+	policy RecipientCheckPolicy) (apiTxReturn APITx, serverResponse string, err error) {
+
+	if policy != RecipientCheckNone {
+		var recipient RecipientStatus
+		recipient, err = CheckEthAddrRecipient(hezClient, toEthAddress, token)
+		if err != nil {
+			err = fmt.Errorf("[L2TransferToEthAddr] Error checking recipient. Error: %s\n", err.Error())
+			return
+		}
+		if !recipient.CanReceive() {
+			err = fmt.Errorf("[L2TransferToEthAddr] Recipient: %s - Token: %d - Error: %w", toEthAddress, token.TokenID, ErrRecipientCannotReceive)
+			return
+		}
+	}
+
+	apiTxReturn, err = NewSignedAPITxToEthAddr(hezClient.EthereumChainID, fromBjjWallet, fromIdx, toEthAddress, amount, feeSelector, token, nonce)
+	if err != nil {
+		err = fmt.Errorf("[L2TransferToEthAddr] Error creating tx to Eth Address: %s - Error: %s\n", toEthAddress, err.Error())
+		return
+	}
+
+	apiTxReturn, serverResponse, err = ExecuteL2Transaction(hezClient, apiTxReturn)
+	if err != nil {
+		err = fmt.Errorf("[L2TransferToEthAddr] Error submiting tx transaction pool endpoint. Error: %s\n", err.Error())
+		return
+	}
+	return
+}
+
+// L2TransfersToEthAddr signs and sends a TransferToEthAddr to each receiver, starting at the given nonce. With
+// RecipientCheckFailFast every recipient is checked before anything is sent and ErrRecipientCannotReceive is returned
+// if any of them can't receive. With RecipientCheckSkip those recipients are flagged as skipped in the results and
+// don't consume a nonce.
+func L2TransfersToEthAddr(hezClient client.HermezClient,
+	fromBjjWallet account.BJJSigner,
+	fromIdx uint64,
+	receivers []TxReceiverMetadata,
+	token hezCommon.Token,
+	nonce int,
+	policy RecipientCheckPolicy) (results []EthAddrTransferResult, err error) {
+
+	results = make([]EthAddrTransferResult, len(receivers))
+	amounts := make([]*big.Int, len(receivers))
+	for i := range receivers {
+		results[i].Receiver = receivers[i]
+		var ok bool
+		amounts[i], ok = big.NewInt(0).SetString(receivers[i].Amount, 10)
+		if !ok {
+			err = fmt.Errorf("[L2TransfersToEthAddr] Invalid amount: %s - Recipient: %s", receivers[i].Amount, receivers[i].ToEthAddr)
+			return
+		}
+		if policy == RecipientCheckNone {
+			continue
+		}
+		results[i].Recipient, err = CheckEthAddrRecipient(hezClient, receivers[i].ToEthAddr, token)
+		if err != nil {
+			err = fmt.Errorf("[L2TransfersToEthAddr] Error checking recipient. Error: %s\n", err.Error())
+			return
+		}
+		if results[i].Recipient.CanReceive() {
+			continue
+		}
+		if policy == RecipientCheckFailFast {
+			err = fmt.Errorf("[L2TransfersToEthAddr] Recipient: %s - Token: %d - Error: %w", receivers[i].ToEthAddr, token.TokenID, ErrRecipientCannotReceive)
+			return
+		}
+		log.Printf("[L2TransfersToEthAddr] Skipping recipient %s. It can't receive token %d\n", receivers[i].ToEthAddr, token.TokenID)
+		results[i].Skipped = true
+	}
+
+	for i := range results {
+		if results[i].Skipped {
+			continue
+		}
+		results[i].APITx, results[i].ServerResponse, err = L2TransferToEthAddr(hezClient, fromBjjWallet, fromIdx,
+			results[i].Receiver.ToEthAddr, amounts[i], hezCommon.FeeSelector(results[i].Receiver.FeeSelector), token, nonce, RecipientCheckNone)
+		if err != nil {
+			err = fmt.Errorf("[L2TransfersToEthAddr] Error sending tx to Eth Address: %s - Error: %s\n", results[i].Receiver.ToEthAddr, err.Error())
+			return
+		}
+		nonce++
+	}
+	return
+}
@@ -0,0 +1,52 @@
+package transaction
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/internal/testutil"
+	"github.com/hermeznetwork/hermez-go-sdk/internal/testwallet"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+)
+
+func TestCheckEthAddrRecipientPaginates(t *testing.T) {
+	node, hezClient := newTestNode(t)
+	recipient := testwallet.New(t, testutil.PvtKeyB)
+	// the account of the token is on the second page of a node capping pages at 20 accounts
+	for i := 0; i < 25; i++ {
+		acc := newTestAccount(recipient, hezCommon.Idx(300+i), 0)
+		acc.ItemID = i + 1
+		acc.Token = account.Token{ID: 100 + i, Symbol: "TKN"}
+		node.accounts = append(node.accounts, acc)
+	}
+	node.accountsPageSize = 20
+
+	status, err := CheckEthAddrRecipient(hezClient, recipient.EthAccount.Address.Hex(), hezCommon.Token{TokenID: 122})
+	if err != nil {
+		t.Fatalf("CheckEthAddrRecipient: %s", err)
+	}
+	if !status.HasAccount || status.AccountIdx != 322 || !status.CanReceive() {
+		t.Errorf("recipient with an account on the second page: %+v", status)
+	}
+
+	status, err = CheckEthAddrRecipient(hezClient, recipient.EthAccount.Address.Hex(), hezCommon.Token{TokenID: 1})
+	if err != nil {
+		t.Fatalf("CheckEthAddrRecipient: %s", err)
+	}
+	if status.HasAccount || status.CanReceive() {
+		t.Errorf("recipient without account for the token: %+v", status)
+	}
+}
+
+func TestCheckEthAddrRecipientNodeError(t *testing.T) {
+	node, hezClient := newTestNode(t)
+	recipient := testwallet.New(t, testutil.PvtKeyB)
+	node.accounts = append(node.accounts, newTestAccount(recipient, 300, 0))
+	node.accountsStatus = http.StatusServiceUnavailable
+
+	// a node failure must not be mistaken for a recipient without account
+	if _, err := CheckEthAddrRecipient(hezClient, recipient.EthAccount.Address.Hex(), hezCommon.Token{TokenID: 1}); err == nil {
+		t.Error("CheckEthAddrRecipient ignored the node failure")
+	}
+}
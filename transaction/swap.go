@@ -0,0 +1,344 @@
+package transaction
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/common/apitypes"
+)
+
+// PartiallySignedAtomicGroupVersion is the version of the PartiallySignedAtomicGroup JSON format
+const PartiallySignedAtomicGroupVersion = 1
+
+var (
+	// ErrPartiallySignedAtomicGroupVersion is returned when parsing a PartiallySignedAtomicGroup written with another
+	// format version
+	ErrPartiallySignedAtomicGroupVersion = errors.New("unsupported partially signed atomic group version")
+	// ErrAtomicLegNotFound is returned when the atomic group has no tx matching a leg the party expects
+	ErrAtomicLegNotFound = errors.New("expected leg not found in the atomic group")
+	// ErrAtomicLegUnexpected is returned when the atomic group has a tx sent by the signer that doesn't match any of
+	// the legs it expects. Signing it would give away funds the party didn't agree on.
+	ErrAtomicLegUnexpected = errors.New("atomic group has a tx of the signer that isn't an expected leg")
+	// ErrAtomicLegTokenMismatch is returned when the token resolved for a leg isn't the TokenID the parties agreed on
+	ErrAtomicLegTokenMismatch = errors.New("token of the atomic leg doesn't match the expected token ID")
+)
+
+// PartiallySignedAtomicGroup is an atomic group passed between the parties of a multi-party atomic transfer. The
+// proposer fixes the txs, and with them the AtomicGroupID, each party checks its legs and adds its signatures, and
+// whoever adds the last signature submits the group.
+type PartiallySignedAtomicGroup struct {
+	Version     int                `json:"version"`
+	ChainID     int                `json:"chainId"`
+	AtomicGroup OfflineAtomicGroup `json:"atomicGroup"`
+}
+
+// AtomicLeg is a tx a party expects to find in the atomic group: the sender sends Amount of the token TokenID to an
+// account of the receiver, paying at most MaxFee. Token symbols aren't unique, so the legs are matched on TokenID and
+// TokenSymbol is only used to describe the leg.
+type AtomicLeg struct {
+	FromHezBjjAddress string
+	ToHezBjjAddress   string
+	TokenID           hezCommon.TokenID
+	TokenSymbol       string
+	Amount            *big.Int
+	MaxFee            hezCommon.FeeSelector
+}
+
+// NewPartiallySignedAtomicGroup wraps an atomic group prepared by the proposer, see PrepareAtomicGroup. The group may
+// already carry the proposer signatures.
+func NewPartiallySignedAtomicGroup(chainID int, atomicGroup OfflineAtomicGroup) (psag PartiallySignedAtomicGroup, err error) {
+	psag = PartiallySignedAtomicGroup{
+		Version:     PartiallySignedAtomicGroupVersion,
+		ChainID:     chainID,
+		AtomicGroup: atomicGroup,
+	}
+	if err = psag.Validate(); err != nil {
+		err = fmt.Errorf("[NewPartiallySignedAtomicGroup] Error: %w", err)
+		return PartiallySignedAtomicGroup{}, err
+	}
+	return
+}
+
+// UnmarshalPartiallySignedAtomicGroup parses a PartiallySignedAtomicGroup and validates it
+func UnmarshalPartiallySignedAtomicGroup(data []byte) (psag PartiallySignedAtomicGroup, err error) {
+	if err = json.Unmarshal(data, &psag); err != nil {
+		err = fmt.Errorf("[UnmarshalPartiallySignedAtomicGroup] Error unmarshaling atomic group - Error: %s", err.Error())
+		return
+	}
+	if err = psag.Validate(); err != nil {
+		err = fmt.Errorf("[UnmarshalPartiallySignedAtomicGroup] Error: %w", err)
+		return PartiallySignedAtomicGroup{}, err
+	}
+	return
+}
+
+// Validate checks the format version, the txs and links of the group, its ID and the signatures already added
+func (p PartiallySignedAtomicGroup) Validate() (err error) {
+	if p.Version != PartiallySignedAtomicGroupVersion {
+		err = fmt.Errorf("[PartiallySignedAtomicGroup][Validate] Version: %d - Error: %w", p.Version, ErrPartiallySignedAtomicGroupVersion)
+		return
+	}
+	if p.ChainID > 65535 || p.ChainID < 1 {
+		err = fmt.Errorf("[PartiallySignedAtomicGroup][Validate] Invalid chainID: %d", p.ChainID)
+		return
+	}
+	return p.AtomicGroup.Validate(p.ChainID)
+}
+
+// IsComplete returns true once every tx of the group is signed
+func (p PartiallySignedAtomicGroup) IsComplete() bool {
+	return len(p.MissingSigners()) == 0
+}
+
+// MissingSigners returns the hez BJJ addresses that still have to sign a tx of the group
+func (p PartiallySignedAtomicGroup) MissingSigners() (hezBjjAddresses []string) {
+	seen := make(map[string]bool)
+	for _, tx := range p.AtomicGroup.Txs {
+		if tx.IsSigned() || seen[tx.FromBJJ] {
+			continue
+		}
+		seen[tx.FromBJJ] = true
+		hezBjjAddresses = append(hezBjjAddresses, tx.FromBJJ)
+	}
+	return
+}
+
+// String describes the group, to be reviewed before signing it
+func (p PartiallySignedAtomicGroup) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Atomic group %s - chain ID %d\n", p.AtomicGroup.ID.String(), p.ChainID)
+	for _, tx := range p.AtomicGroup.Txs {
+		fmt.Fprintf(&sb, "  %s\n", tx)
+	}
+	return sb.String()
+}
+
+// VerifyAtomicLegs checks that every leg has a matching tx in the group. The receiver of each tx is resolved with the
+// hermez node, since transfers only carry the receiver account index.
+func VerifyAtomicLegs(hezClient client.HermezClient, psag PartiallySignedAtomicGroup, legs ...AtomicLeg) (err error) {
+	_, err = matchAtomicLegs(hezClient, psag, legs)
+	return
+}
+
+// SignPartiallySignedAtomicGroup validates the group, checks the legs the party expects with VerifyAtomicLegs and
+// signs every unsigned tx sent by one of the signers. Every tx of the signers must be one of the expected legs,
+// otherwise nothing is signed and ErrAtomicLegUnexpected is returned. It returns how many txs were signed.
+func SignPartiallySignedAtomicGroup(hezClient client.HermezClient, psag *PartiallySignedAtomicGroup, legs []AtomicLeg, signers ...account.BJJSigner) (signed int, err error) {
+	if err = psag.Validate(); err != nil {
+		err = fmt.Errorf("[SignPartiallySignedAtomicGroup] Error validating atomic group - Error: %w", err)
+		return
+	}
+	if psag.ChainID != hezClient.EthereumChainID {
+		err = fmt.Errorf("[SignPartiallySignedAtomicGroup] Group chain ID: %d - Client chain ID: %d - Error: %w", psag.ChainID, hezClient.EthereumChainID, ErrOfflineChainIDMismatch)
+		return
+	}
+	expected, err := matchAtomicLegs(hezClient, *psag, legs)
+	if err != nil {
+		err = fmt.Errorf("[SignPartiallySignedAtomicGroup] Error: %w", err)
+		return
+	}
+	signersByBJJ := make(map[string]account.BJJSigner, len(signers))
+	for _, signer := range signers {
+		signersByBJJ[BjjToString(signer.BJJPublicKey())] = signer
+	}
+	for i, tx := range psag.AtomicGroup.Txs {
+		if _, ok := signersByBJJ[tx.FromBJJ]; ok && !expected[i] {
+			err = fmt.Errorf("[SignPartiallySignedAtomicGroup] TxID: %s - Error: %w", tx.TxID.String(), ErrAtomicLegUnexpected)
+			return
+		}
+	}
+	for i := range psag.AtomicGroup.Txs {
+		offlineTx := &psag.AtomicGroup.Txs[i]
+		signer, ok := signersByBJJ[offlineTx.FromBJJ]
+		if !ok || offlineTx.IsSigned() {
+			continue
+		}
+		if err = offlineTx.sign(psag.ChainID, signer); err != nil {
+			err = fmt.Errorf("[SignPartiallySignedAtomicGroup] Error signing tx. TxID: %s - Error: %w", offlineTx.TxID.String(), err)
+			return
+		}
+		signed++
+	}
+	return
+}
+
+// SubmitPartiallySignedAtomicGroup sends the fully signed group with SendAtomicTxsGroup. It fails with
+// ErrOfflineTxNotSigned while a party is still missing, see MissingSigners.
+func SubmitPartiallySignedAtomicGroup(hezClient client.HermezClient, psag PartiallySignedAtomicGroup) (serverResponse string, err error) {
+	if err = psag.Validate(); err != nil {
+		err = fmt.Errorf("[SubmitPartiallySignedAtomicGroup] Error validating atomic group - Error: %w", err)
+		return
+	}
+	results, err := BroadcastOfflineFile(hezClient, NewOfflineFile(psag.ChainID, nil, []OfflineAtomicGroup{psag.AtomicGroup}))
+	if err != nil {
+		err = fmt.Errorf("[SubmitPartiallySignedAtomicGroup] Error: %w", err)
+		return
+	}
+	serverResponse = results[0].ServerResponse
+	return
+}
+
+// matchAtomicLegs returns the positions of the txs matching the legs, each tx can only match one leg. The token of a
+// tx is its signed TokenID, the TokenSymbol of the group comes from the proposer and isn't trusted.
+func matchAtomicLegs(hezClient client.HermezClient, psag PartiallySignedAtomicGroup, legs []AtomicLeg) (matched map[int]bool, err error) {
+	matched = make(map[int]bool, len(legs))
+	receivers := make(map[hezCommon.Idx]account.Account)
+	for _, leg := range legs {
+		found := false
+		for i, tx := range psag.AtomicGroup.Txs {
+			if matched[i] || !sameHezBJJ(tx.FromBJJ, leg.FromHezBjjAddress) ||
+				tx.TokenID != leg.TokenID || leg.Amount == nil ||
+				tx.Amount != leg.Amount.String() || tx.Fee > leg.MaxFee {
+				continue
+			}
+			receiver := BjjToString(tx.ToBJJ)
+			if tx.ToIdx != 0 {
+				receiverAccount, ok := receivers[tx.ToIdx]
+				if !ok {
+					receiverAccount, err = account.GetAccountByIdx(hezClient, IdxToHez(tx.ToIdx, tx.TokenSymbol))
+					if err != nil {
+						err = fmt.Errorf("[matchAtomicLegs] Error obtaining receiver account. TxID: %s - Error: %w", tx.TxID.String(), err)
+						return
+					}
+					receivers[tx.ToIdx] = receiverAccount
+				}
+				// an account of a look-alike token of the receiver would still hold its BJJ
+				if hezCommon.TokenID(receiverAccount.Token.ID) != tx.TokenID {
+					continue
+				}
+				receiver = receiverAccount.BJJAddress
+			}
+			if !sameHezBJJ(receiver, leg.ToHezBjjAddress) {
+				continue
+			}
+			matched[i] = true
+			found = true
+			break
+		}
+		if !found {
+			err = fmt.Errorf("[matchAtomicLegs] Leg: %s %s (token ID %d) from %s to %s - Error: %w", leg.Amount, leg.TokenSymbol, leg.TokenID, leg.FromHezBjjAddress, leg.ToHezBjjAddress, ErrAtomicLegNotFound)
+			return
+		}
+	}
+	return
+}
+
+// sameHezBJJ returns true when both hez BJJ addresses are valid and hold the same key
+func sameHezBJJ(a, b string) bool {
+	bjjA, err := apitypes.HezBJJ(a).ToBJJ()
+	if err != nil {
+		return false
+	}
+	bjjB, err := apitypes.HezBJJ(b).ToBJJ()
+	if err != nil {
+		return false
+	}
+	return bjjA == bjjB
+}
+
+// AtomicSwapParty is one side of a two-party atomic swap: the party sends Amount of the token TokenID to the other
+// one. TokenSymbol picks the account of the party, it must hold the token TokenID.
+type AtomicSwapParty struct {
+	HezBjjAddress string
+	TokenID       hezCommon.TokenID
+	TokenSymbol   string
+	Amount        *big.Int
+}
+
+// AtomicSwap describes a two-party swap, each party sends its token to the other one in the same atomic group
+type AtomicSwap struct {
+	Proposer           AtomicSwapParty
+	Counterparty       AtomicSwapParty
+	FeeRangeSelectedID int
+}
+
+// Legs returns the two legs of the swap, proposer to counterparty and counterparty to proposer
+func (s AtomicSwap) Legs() []AtomicLeg {
+	return []AtomicLeg{
+		{
+			FromHezBjjAddress: s.Proposer.HezBjjAddress,
+			ToHezBjjAddress:   s.Counterparty.HezBjjAddress,
+			TokenID:           s.Proposer.TokenID,
+			TokenSymbol:       s.Proposer.TokenSymbol,
+			Amount:            s.Proposer.Amount,
+			MaxFee:            hezCommon.FeeSelector(uint8(s.FeeRangeSelectedID)),
+		},
+		{
+			FromHezBjjAddress: s.Counterparty.HezBjjAddress,
+			ToHezBjjAddress:   s.Proposer.HezBjjAddress,
+			TokenID:           s.Counterparty.TokenID,
+			TokenSymbol:       s.Counterparty.TokenSymbol,
+			Amount:            s.Counterparty.Amount,
+			MaxFee:            hezCommon.FeeSelector(uint8(s.FeeRangeSelectedID)),
+		},
+	}
+}
+
+// ProposeAtomicSwap prepares the unsigned atomic group of the swap. Only the public keys of the parties are needed,
+// the proposer signs its leg afterwards with SignAtomicSwap, like the counterparty does.
+func ProposeAtomicSwap(hezClient client.HermezClient, swap AtomicSwap) (psag PartiallySignedAtomicGroup, err error) {
+	builder := NewAtomicGroupBuilder()
+	var senders []string
+	for _, leg := range swap.Legs() {
+		var sender *account.WatchOnlyBJJSigner
+		sender, err = account.NewWatchOnlyBJJSigner(leg.FromHezBjjAddress)
+		if err != nil {
+			err = fmt.Errorf("[ProposeAtomicSwap] Error: %s", err.Error())
+			return
+		}
+		var offlineTx OfflineTx
+		offlineTx, err = PrepareL2Transfer(hezClient, sender, leg.ToHezBjjAddress, strings.ToUpper(leg.TokenSymbol), leg.Amount, swap.FeeRangeSelectedID)
+		if err != nil {
+			err = fmt.Errorf("[ProposeAtomicSwap] Error preparing leg from %s - Error: %s", leg.FromHezBjjAddress, err.Error())
+			return
+		}
+		if offlineTx.TokenID != leg.TokenID {
+			err = fmt.Errorf("[ProposeAtomicSwap] Leg from %s resolved %s to token ID %d, expected %d - Error: %w",
+				leg.FromHezBjjAddress, leg.TokenSymbol, offlineTx.TokenID, leg.TokenID, ErrAtomicLegTokenMismatch)
+			return
+		}
+		var tx hezCommon.PoolL2Tx
+		if tx, err = offlineTx.PoolL2Tx(); err != nil {
+			return
+		}
+		builder.Add(tx)
+		senders = append(senders, offlineTx.FromBJJ)
+	}
+	builder.Require(0, 1).Require(1, 0)
+	atomicGroup, err := builder.Build()
+	if err != nil {
+		err = fmt.Errorf("[ProposeAtomicSwap] Error building atomic group - Error: %w", err)
+		return
+	}
+
+	offlineGroup := OfflineAtomicGroup{ID: atomicGroup.ID}
+	for i := range atomicGroup.Txs {
+		offlineGroup.Txs = append(offlineGroup.Txs, NewOfflineTx(atomicGroup.Txs[i], senders[i]))
+	}
+	psag, err = NewPartiallySignedAtomicGroup(hezClient.EthereumChainID, offlineGroup)
+	if err != nil {
+		err = fmt.Errorf("[ProposeAtomicSwap] Error: %w", err)
+		return
+	}
+	return
+}
+
+// SignAtomicSwap checks that the group holds exactly the two legs of the swap and adds the signature of the signer,
+// who can be either the proposer or the counterparty
+func SignAtomicSwap(hezClient client.HermezClient, psag *PartiallySignedAtomicGroup, swap AtomicSwap, signer account.BJJSigner) (err error) {
+	if len(psag.AtomicGroup.Txs) != 2 {
+		err = fmt.Errorf("[SignAtomicSwap] Atomic group has %d txs - Error: %w", len(psag.AtomicGroup.Txs), ErrAtomicLegUnexpected)
+		return
+	}
+	if _, err = SignPartiallySignedAtomicGroup(hezClient, psag, swap.Legs(), signer); err != nil {
+		err = fmt.Errorf("[SignAtomicSwap] Error: %w", err)
+		return
+	}
+	return
+}
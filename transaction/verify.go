@@ -0,0 +1,317 @@
+package transaction
+
+import (
+	"fmt"
+	"math/big"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/common/apitypes"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+// TxFieldMismatch is a field whose value differs between the expected tx and the one returned by the coordinator
+type TxFieldMismatch struct {
+	Field    string
+	Expected string
+	Actual   string
+}
+
+// TxVerification is the result of checking locally a tx returned by a coordinator
+type TxVerification struct {
+	TxID         hezCommon.TxID
+	ComputedTxID hezCommon.TxID
+	SenderBJJ    babyjub.PublicKeyComp
+	// ValidTxID is true when the TxID matches the one computed from the tx fields
+	ValidTxID bool
+	// ValidSignature is true when the signature of the tx hash was produced by SenderBJJ
+	ValidSignature bool
+	// Mismatches lists the fields that differ from the expected tx, if one was given
+	Mismatches []TxFieldMismatch
+}
+
+// OK returns true when the TxID and the signature are valid and no field differs from the expected tx
+func (v TxVerification) OK() bool {
+	return v.ValidTxID && v.ValidSignature && len(v.Mismatches) < 1
+}
+
+// VerifyPoolL2Tx recomputes the TxID and the hash to sign of the tx for the chain ID and checks its signature
+// against the sender BJJ public key
+func VerifyPoolL2Tx(chainID int, tx hezCommon.PoolL2Tx, senderBJJ babyjub.PublicKeyComp) (verification TxVerification) {
+	verification.TxID = tx.TxID
+	verification.SenderBJJ = senderBJJ
+	if err := tx.SetID(); err == nil {
+		verification.ComputedTxID = tx.TxID
+		verification.ValidTxID = verification.ComputedTxID == verification.TxID
+	}
+	verification.ValidSignature = tx.VerifySignature(uint16(chainID), senderBJJ)
+	return
+}
+
+// CompareTxs lists the signed fields that differ between the expected tx and the actual one
+func CompareTxs(expected hezCommon.PoolL2Tx, actual hezCommon.PoolL2Tx) (mismatches []TxFieldMismatch) {
+	compare := func(field string, expectedValue, actualValue string) {
+		if expectedValue != actualValue {
+			mismatches = append(mismatches, TxFieldMismatch{Field: field, Expected: expectedValue, Actual: actualValue})
+		}
+	}
+	compare("type", string(expected.Type), string(actual.Type))
+	compare("fromIdx", expected.FromIdx.String(), actual.FromIdx.String())
+	compare("toIdx", expected.ToIdx.String(), actual.ToIdx.String())
+	compare("toEthAddr", expected.ToEthAddr.Hex(), actual.ToEthAddr.Hex())
+	compare("toBJJ", expected.ToBJJ.String(), actual.ToBJJ.String())
+	compare("tokenId", fmt.Sprint(expected.TokenID), fmt.Sprint(actual.TokenID))
+	compare("amount", bigIntString(expected.Amount), bigIntString(actual.Amount))
+	compare("fee", fmt.Sprint(expected.Fee), fmt.Sprint(actual.Fee))
+	compare("nonce", fmt.Sprint(expected.Nonce), fmt.Sprint(actual.Nonce))
+	compare("maxNumBatch", fmt.Sprint(expected.MaxNumBatch), fmt.Sprint(actual.MaxNumBatch))
+	compare("rqFromIdx", expected.RqFromIdx.String(), actual.RqFromIdx.String())
+	compare("rqToIdx", expected.RqToIdx.String(), actual.RqToIdx.String())
+	compare("rqToEthAddr", expected.RqToEthAddr.Hex(), actual.RqToEthAddr.Hex())
+	compare("rqToBJJ", expected.RqToBJJ.String(), actual.RqToBJJ.String())
+	compare("rqTokenId", fmt.Sprint(expected.RqTokenID), fmt.Sprint(actual.RqTokenID))
+	compare("rqAmount", bigIntString(expected.RqAmount), bigIntString(actual.RqAmount))
+	compare("rqFee", fmt.Sprint(expected.RqFee), fmt.Sprint(actual.RqFee))
+	compare("rqNonce", fmt.Sprint(expected.RqNonce), fmt.Sprint(actual.RqNonce))
+	return
+}
+
+// VerifyPoolTx checks the TxID and the signature of a tx returned by the coordinator pool, with the sender BJJ public
+// key pulled from /v1/accounts
+func VerifyPoolTx(hezClient client.HermezClient, poolTx PoolTxAPI) (verification TxVerification, err error) {
+	tx, err := poolTx.PoolL2Tx()
+	if err != nil {
+		err = fmt.Errorf("[VerifyPoolTx] TxID: %s - Error: %s", poolTx.TxID.String(), err.Error())
+		return
+	}
+	senderBJJ, err := getSenderBJJ(hezClient, string(poolTx.FromIdx))
+	if err != nil {
+		err = fmt.Errorf("[VerifyPoolTx] TxID: %s - Error: %w", poolTx.TxID.String(), err)
+		return
+	}
+	return VerifyPoolL2Tx(hezClient.EthereumChainID, tx, senderBJJ), nil
+}
+
+// VerifyAPITx checks the TxID and the signature of an APITx, with the sender BJJ public key pulled from /v1/accounts
+func VerifyAPITx(hezClient client.HermezClient, apiTx APITx) (verification TxVerification, err error) {
+	tx, err := apiTx.PoolL2Tx()
+	if err != nil {
+		err = fmt.Errorf("[VerifyAPITx] TxID: %s - Error: %s", apiTx.TxID.String(), err.Error())
+		return
+	}
+	senderBJJ, err := getSenderBJJ(hezClient, apiTx.FromIdx)
+	if err != nil {
+		err = fmt.Errorf("[VerifyAPITx] TxID: %s - Error: %w", apiTx.TxID.String(), err)
+		return
+	}
+	return VerifyPoolL2Tx(hezClient.EthereumChainID, tx, senderBJJ), nil
+}
+
+// AuditSubmittedTx pulls from the pool the tx we submitted, checks its TxID and signature and reports the fields
+// that differ from what we signed
+func AuditSubmittedTx(hezClient client.HermezClient, submitted APITx) (verification TxVerification, err error) {
+	expected, err := submitted.PoolL2Tx()
+	if err != nil {
+		err = fmt.Errorf("[AuditSubmittedTx] TxID: %s - Error: %s", submitted.TxID.String(), err.Error())
+		return
+	}
+	verification, err = auditSubmittedPoolL2Tx(hezClient, expected)
+	if err != nil {
+		err = fmt.Errorf("[AuditSubmittedTx] TxID: %s - Error: %w", submitted.TxID.String(), err)
+		return
+	}
+	return
+}
+
+// AuditSubmittedAtomicGroup runs AuditSubmittedTx on every tx of the atomic group we submitted, Rq fields included
+func AuditSubmittedAtomicGroup(hezClient client.HermezClient, atomicGroup hezCommon.AtomicGroup) (verifications []TxVerification, err error) {
+	for _, expected := range atomicGroup.Txs {
+		var verification TxVerification
+		verification, err = auditSubmittedPoolL2Tx(hezClient, expected)
+		if err != nil {
+			err = fmt.Errorf("[AuditSubmittedAtomicGroup] Atomic group: %s - TxID: %s - Error: %w", atomicGroup.ID.String(), expected.TxID.String(), err)
+			return
+		}
+		verifications = append(verifications, verification)
+	}
+	return
+}
+
+// auditSubmittedPoolL2Tx pulls the expected tx from the pool, verifies it and compares it with the expected one
+func auditSubmittedPoolL2Tx(hezClient client.HermezClient, expected hezCommon.PoolL2Tx) (verification TxVerification, err error) {
+	poolTx, err := GetTransactionPool(hezClient, expected.TxID)
+	if err != nil {
+		return
+	}
+	actual, err := poolTx.PoolL2Tx()
+	if err != nil {
+		return
+	}
+	verification, err = VerifyPoolTx(hezClient, poolTx)
+	if err != nil {
+		return
+	}
+	verification.Mismatches = CompareTxs(expected, actual)
+	if expected.Signature != actual.Signature {
+		verification.Mismatches = append(verification.Mismatches, TxFieldMismatch{Field: "signature", Expected: expected.Signature.String(), Actual: actual.Signature.String()})
+	}
+	return
+}
+
+// AuditTransactionsPool checks the TxID and the signature of every tx in the boot coordinator pool
+func AuditTransactionsPool(hezClient client.HermezClient) (verifications []TxVerification, err error) {
+	transactions, err := GetTransactionsInPool(hezClient)
+	if err != nil {
+		err = fmt.Errorf("[AuditTransactionsPool] Error: %w", err)
+		return
+	}
+	for _, poolTx := range transactions.Transactions {
+		var verification TxVerification
+		verification, err = VerifyPoolTx(hezClient, poolTx)
+		if err != nil {
+			err = fmt.Errorf("[AuditTransactionsPool] Error: %w", err)
+			return
+		}
+		verifications = append(verifications, verification)
+	}
+	return
+}
+
+// PoolL2Tx rebuilds the signed PoolL2Tx from the pool tx returned by the API. The API returns the effective
+// recipient, so ToEthAddr and ToBJJ are only kept for the tx types that sign them.
+func (tx PoolTxAPI) PoolL2Tx() (poolL2Tx hezCommon.PoolL2Tx, err error) {
+	poolL2Tx = hezCommon.PoolL2Tx{
+		TxID:        tx.TxID,
+		Type:        tx.Type,
+		TokenID:     hezCommon.TokenID(tx.Token.ID),
+		Fee:         tx.Fee,
+		Nonce:       tx.Nonce,
+		MaxNumBatch: tx.MaxNumBatch,
+		Signature:   tx.Signature,
+		RqTokenID:   tx.RqTokenID,
+		RqFee:       tx.RqFee,
+		RqNonce:     tx.RqNonce,
+	}
+	if poolL2Tx.FromIdx, err = hezIdxToIdx(string(tx.FromIdx)); err != nil {
+		return
+	}
+	if poolL2Tx.ToIdx, err = hezIdxToIdx(string(tx.ToIdx)); err != nil {
+		return
+	}
+	if poolL2Tx.Amount, err = bigIntStrToBigInt(tx.Amount); err != nil {
+		return
+	}
+	switch tx.Type {
+	case hezCommon.TxTypeTransferToEthAddr:
+		if poolL2Tx.ToEthAddr, err = hezEthAddrToEthAddr(string(tx.EffectiveToEthAddr)); err != nil {
+			return
+		}
+	case hezCommon.TxTypeTransferToBJJ:
+		poolL2Tx.ToEthAddr = hezCommon.FFAddr
+		if poolL2Tx.ToBJJ, err = hezBJJToBJJ(string(tx.EffectiveToBJJ)); err != nil {
+			return
+		}
+	}
+	if poolL2Tx.RqFromIdx, err = hezIdxToIdx(string(tx.RqFromIdx)); err != nil {
+		return
+	}
+	if poolL2Tx.RqToIdx, err = hezIdxToIdx(string(tx.RqToIdx)); err != nil {
+		return
+	}
+	if poolL2Tx.RqToEthAddr, err = hezEthAddrToEthAddr(string(tx.RqToEthAddr)); err != nil {
+		return
+	}
+	if poolL2Tx.RqToBJJ, err = hezBJJToBJJ(string(tx.RqToBJJ)); err != nil {
+		return
+	}
+	if len(tx.RqAmount) > 0 {
+		if poolL2Tx.RqAmount, err = bigIntStrToBigInt(tx.RqAmount); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// PoolL2Tx rebuilds the signed PoolL2Tx from the APITx
+func (tx APITx) PoolL2Tx() (poolL2Tx hezCommon.PoolL2Tx, err error) {
+	poolL2Tx = hezCommon.PoolL2Tx{
+		TxID:    tx.TxID,
+		Type:    hezCommon.TxType(tx.Type),
+		TokenID: hezCommon.TokenID(tx.TokenID),
+		Fee:     hezCommon.FeeSelector(tx.Fee),
+		Nonce:   hezCommon.Nonce(tx.Nonce),
+	}
+	if poolL2Tx.FromIdx, err = hezIdxToIdx(tx.FromIdx); err != nil {
+		return
+	}
+	if poolL2Tx.ToIdx, err = hezIdxToIdx(tx.ToIdx); err != nil {
+		return
+	}
+	if poolL2Tx.ToEthAddr, err = hezEthAddrToEthAddr(tx.ToEthAddr); err != nil {
+		return
+	}
+	if poolL2Tx.ToBJJ, err = hezBJJToBJJ(tx.ToBJJ); err != nil {
+		return
+	}
+	if poolL2Tx.Type == hezCommon.TxTypeTransferToBJJ {
+		poolL2Tx.ToEthAddr = hezCommon.FFAddr
+	}
+	if poolL2Tx.Amount, err = bigIntStrToBigInt(apitypes.BigIntStr(tx.Amount)); err != nil {
+		return
+	}
+	if err = poolL2Tx.Signature.UnmarshalText([]byte(tx.Signature)); err != nil {
+		err = fmt.Errorf("invalid signature: %s", tx.Signature)
+		return
+	}
+	return
+}
+
+// getSenderBJJ pulls from /v1/accounts the BJJ public key of the account with the hez account index
+func getSenderBJJ(hezClient client.HermezClient, fromIdx string) (senderBJJ babyjub.PublicKeyComp, err error) {
+	senderAccount, err := account.GetAccountByIdx(hezClient, fromIdx)
+	if err != nil {
+		return
+	}
+	return hezBJJToBJJ(senderAccount.BJJAddress)
+}
+
+func hezIdxToIdx(hezIdx string) (idx hezCommon.Idx, err error) {
+	queryAccount, err := hezCommon.StringToIdx(hezIdx, "accountIndex")
+	if err != nil || queryAccount.AccountIndex == nil {
+		return
+	}
+	return *queryAccount.AccountIndex, nil
+}
+
+func hezEthAddrToEthAddr(hezEthAddr string) (ethAddr ethCommon.Address, err error) {
+	addr, err := hezCommon.HezStringToEthAddr(hezEthAddr, "hezEthereumAddress")
+	if err != nil || addr == nil {
+		return
+	}
+	return *addr, nil
+}
+
+func hezBJJToBJJ(hezBJJ string) (bjj babyjub.PublicKeyComp, err error) {
+	pkComp, err := hezCommon.HezStringToBJJ(hezBJJ, "BJJ")
+	if err != nil || pkComp == nil {
+		return
+	}
+	return *pkComp, nil
+}
+
+func bigIntStrToBigInt(s apitypes.BigIntStr) (*big.Int, error) {
+	value, ok := new(big.Int).SetString(string(s), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount: %s", s)
+	}
+	return value, nil
+}
+
+func bigIntString(value *big.Int) string {
+	if value == nil {
+		return "0"
+	}
+	return value.String()
+}
@@ -0,0 +1,129 @@
+package transaction
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/hermeznetwork/hermez-go-sdk/account"
+	"github.com/hermeznetwork/hermez-go-sdk/internal/testutil"
+	"github.com/hermeznetwork/hermez-go-sdk/internal/testwallet"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/common/apitypes"
+)
+
+func newTestAccount(wallet account.BJJWallet, idx hezCommon.Idx, nonce int) account.Account {
+	return account.Account{
+		AccountIndex:       IdxToHez(idx, "HEZ"),
+		Balance:            "1000000000000000000",
+		BJJAddress:         wallet.HezBjjAddress,
+		HezEthereumAddress: wallet.HezEthAddress,
+		Nonce:              nonce,
+		Token:              account.Token{ID: 1, Symbol: "HEZ"},
+	}
+}
+
+// poolTxAPI returns the tx the way the pool API serves it, with the effective recipient of the receiver account
+func poolTxAPI(tx hezCommon.PoolL2Tx, receiver account.Account) PoolTxAPI {
+	poolTx := PoolTxAPI{
+		TxID:               tx.TxID,
+		Type:               tx.Type,
+		FromIdx:            apitypes.HezIdx(IdxToHez(tx.FromIdx, "HEZ")),
+		ToIdx:              apitypes.HezIdx(IdxToHez(tx.ToIdx, "HEZ")),
+		EffectiveToEthAddr: apitypes.HezEthAddr(receiver.HezEthereumAddress),
+		EffectiveToBJJ:     apitypes.HezBJJ(receiver.BJJAddress),
+		Amount:             apitypes.BigIntStr(tx.Amount.String()),
+		Fee:                tx.Fee,
+		Nonce:              tx.Nonce,
+		State:              hezCommon.PoolL2TxStatePending,
+		Signature:          tx.Signature,
+		RqTokenID:          tx.RqTokenID,
+		RqFee:              tx.RqFee,
+		RqNonce:            tx.RqNonce,
+	}
+	poolTx.Token.ID = int(tx.TokenID)
+	poolTx.Token.Symbol = "HEZ"
+	if tx.RqFromIdx != 0 {
+		poolTx.RqFromIdx = apitypes.HezIdx(IdxToHez(tx.RqFromIdx, "HEZ"))
+	}
+	if tx.RqToIdx != 0 {
+		poolTx.RqToIdx = apitypes.HezIdx(IdxToHez(tx.RqToIdx, "HEZ"))
+	}
+	if tx.RqToEthAddr != hezCommon.EmptyAddr {
+		poolTx.RqToEthAddr = apitypes.HezEthAddr(ethAddrToHez(tx.RqToEthAddr))
+	}
+	if tx.RqToBJJ != hezCommon.EmptyBJJComp {
+		poolTx.RqToBJJ = apitypes.HezBJJ(BjjToString(tx.RqToBJJ))
+	}
+	if tx.RqAmount != nil {
+		poolTx.RqAmount = apitypes.BigIntStr(tx.RqAmount.String())
+	}
+	return poolTx
+}
+
+func TestAtomicTransferRoundTripVerifies(t *testing.T) {
+	node, hezClient := newTestNode(t)
+	walletA := testwallet.New(t, testutil.PvtKeyA)
+	walletB := testwallet.New(t, testutil.PvtKeyB)
+	accountA := newTestAccount(walletA, 256, 3)
+	accountB := newTestAccount(walletB, 257, 0)
+	node.accounts = []account.Account{accountA, accountB}
+
+	items := []AtomicTxItem{
+		{
+			SenderBjjWallet:       walletA,
+			ReceiverAddress:       walletB.EthAccount.Address.Hex(),
+			TokenSymbolToTransfer: "HEZ",
+			Amount:                big.NewInt(1000),
+			FeeRangeSelectedID:    126,
+			RqOffSet:              1,
+		},
+		{
+			SenderBjjWallet:       walletB,
+			ReceiverAddress:       walletA.EthAccount.Address.Hex(),
+			TokenSymbolToTransfer: "HEZ",
+			Amount:                big.NewInt(2000),
+			FeeRangeSelectedID:    126,
+			RqOffSet:              7,
+		},
+	}
+	atomicGroup := hezCommon.AtomicGroup{}
+	var err error
+	if atomicGroup.Txs, err = CreateFullTxs(hezClient, items); err != nil {
+		t.Fatalf("CreateFullTxs: %s", err)
+	}
+	atomicGroup = SetAtomicGroupID(atomicGroup)
+	for i := range atomicGroup.Txs {
+		txHash, err := atomicGroup.Txs[i].HashToSign(uint16(hezClient.EthereumChainID))
+		if err != nil {
+			t.Fatalf("HashToSign: %s", err)
+		}
+		signature, err := items[i].SenderBjjWallet.SignPoseidon(txHash)
+		if err != nil {
+			t.Fatalf("SignPoseidon: %s", err)
+		}
+		atomicGroup.Txs[i].Signature = signature.Compress()
+	}
+
+	receivers := []account.Account{accountB, accountA}
+	for i, tx := range atomicGroup.Txs {
+		poolTx := poolTxAPI(tx, receivers[i])
+		node.setPoolTx(poolTx)
+		verification, err := VerifyPoolTx(hezClient, poolTx)
+		if err != nil {
+			t.Fatalf("VerifyPoolTx: %s", err)
+		}
+		if !verification.ValidTxID || !verification.ValidSignature {
+			t.Errorf("tx %d: ValidTxID %t - ValidSignature %t", i, verification.ValidTxID, verification.ValidSignature)
+		}
+	}
+
+	verifications, err := AuditSubmittedAtomicGroup(hezClient, atomicGroup)
+	if err != nil {
+		t.Fatalf("AuditSubmittedAtomicGroup: %s", err)
+	}
+	for i, verification := range verifications {
+		if !verification.OK() {
+			t.Errorf("tx %d not verified: %+v", i, verification)
+		}
+	}
+}
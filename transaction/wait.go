@@ -0,0 +1,179 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hermeznetwork/hermez-go-sdk/client"
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+)
+
+const (
+	// DefaultTxPollInterval is the time WaitForTx waits between two queries
+	DefaultTxPollInterval = 10 * time.Second
+	// DefaultTxDroppedAfter is how long a tx can be missing from both the pool and the history before WaitForTx
+	// reports it as dropped
+	DefaultTxDroppedAfter = 2 * time.Minute
+)
+
+// ErrTxNotFound is returned when the hermez node doesn't know the tx
+var ErrTxNotFound = errors.New("tx not found")
+
+// TxOutcomeState is the final state of a tx, or pending while it is still in the pool
+type TxOutcomeState string
+
+const (
+	// TxOutcomePending means that the tx is still waiting in the pool or being forged
+	TxOutcomePending TxOutcomeState = "pending"
+	// TxOutcomeForged means that the tx was forged, BatchNum and Timestamp tell where and when
+	TxOutcomeForged TxOutcomeState = "forged"
+	// TxOutcomeInvalid means that the coordinator rejected the tx, ErrorCode, ErrorType and Info tell why
+	TxOutcomeInvalid TxOutcomeState = "invalid"
+	// TxOutcomeDropped means that the tx is neither in the pool nor in the history, e.g. it expired or was purged
+	TxOutcomeDropped TxOutcomeState = "dropped"
+)
+
+// TxOutcome is what happened to a tx sent to the pool
+type TxOutcome struct {
+	TxID      hezCommon.TxID
+	State     TxOutcomeState
+	BatchNum  hezCommon.BatchNum
+	Timestamp time.Time
+	ErrorCode int
+	ErrorType string
+	Info      string
+}
+
+// IsFinal returns true once the tx is forged, invalid or dropped, its state won't change anymore
+func (o TxOutcome) IsFinal() bool {
+	return o.State != TxOutcomePending
+}
+
+// TxWaitResult is sent by WatchTx once the wait is over
+type TxWaitResult struct {
+	Outcome TxOutcome
+	Err     error
+}
+
+// WaitOptions configures WaitForTx. Zero values take the defaults, a zero Timeout only stops when ctx is done.
+type WaitOptions struct {
+	PollInterval time.Duration
+	DroppedAfter time.Duration
+	Timeout      time.Duration
+}
+
+// WaitForTx polls the pool of hezClient.CurrentCoordinatorURL and then the history of the boot coordinator until
+// the tx is forged, invalid or dropped. When ctx is done or the timeout expires it returns the last outcome read, in
+// pending state, together with the context error.
+func WaitForTx(ctx context.Context, hezClient client.HermezClient, txID hezCommon.TxID, opts WaitOptions) (outcome TxOutcome, err error) {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultTxPollInterval
+	}
+	if opts.DroppedAfter <= 0 {
+		opts.DroppedAfter = DefaultTxDroppedAfter
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+
+	outcome = TxOutcome{TxID: txID, State: TxOutcomePending}
+	var missingSince time.Time
+	for {
+		var found bool
+		found, err = pollTxOutcome(hezClient, &outcome)
+		if err != nil {
+			err = fmt.Errorf("[WaitForTx] TxID: %s - Error: %w", txID.String(), err)
+			return
+		}
+		if outcome.IsFinal() {
+			return
+		}
+		switch {
+		case found:
+			missingSince = time.Time{}
+		case missingSince.IsZero():
+			missingSince = time.Now()
+		case time.Since(missingSince) >= opts.DroppedAfter:
+			outcome.State = TxOutcomeDropped
+			return
+		}
+		select {
+		case <-ctx.Done():
+			err = fmt.Errorf("[WaitForTx] TxID: %s - State: %s - Error: %w", txID.String(), outcome.State, ctx.Err())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// WatchTx runs WaitForTx in the background. The channel receives a single result and is closed afterwards.
+func WatchTx(ctx context.Context, hezClient client.HermezClient, txID hezCommon.TxID, opts WaitOptions) <-chan TxWaitResult {
+	results := make(chan TxWaitResult, 1)
+	go func() {
+		defer close(results)
+		outcome, err := WaitForTx(ctx, hezClient, txID, opts)
+		results <- TxWaitResult{Outcome: outcome, Err: err}
+	}()
+	return results
+}
+
+// WaitForTxCallback runs WaitForTx in the background and calls callback with its outcome
+func WaitForTxCallback(ctx context.Context, hezClient client.HermezClient, txID hezCommon.TxID, opts WaitOptions, callback func(TxOutcome, error)) {
+	go func() {
+		callback(WaitForTx(ctx, hezClient, txID, opts))
+	}()
+}
+
+// pollTxOutcome reads the tx from the pool and, once forged or out of the pool, from the history. found is false when
+// neither of them has the tx.
+func pollTxOutcome(hezClient client.HermezClient, outcome *TxOutcome) (found bool, err error) {
+	poolTx, err := GetTransactionPool(hezClient, outcome.TxID)
+	inPool := err == nil
+	if err != nil && !errors.Is(err, ErrTxNotFound) {
+		return
+	}
+	if inPool {
+		outcome.ErrorCode = poolTx.ErrorCode
+		outcome.ErrorType = poolTx.ErrorType
+		outcome.Info = poolTx.Info
+		switch poolTx.State {
+		case hezCommon.PoolL2TxStateInvalid:
+			outcome.State = TxOutcomeInvalid
+			return true, nil
+		case hezCommon.PoolL2TxStatePending, hezCommon.PoolL2TxStateForging:
+			return true, nil
+		}
+	}
+
+	forged, inHistory, err := getForgedTx(hezClient, outcome.TxID)
+	if err != nil {
+		return
+	}
+	if inHistory && forged.ForgedBatchNum() > 0 {
+		outcome.State = TxOutcomeForged
+		outcome.BatchNum = forged.ForgedBatchNum()
+		outcome.Timestamp = forged.Timestamp
+	}
+	// a tx forged in the pool but not in the history yet is still found, the boot coordinator is syncing it
+	return inPool || inHistory, nil
+}
+
+// getForgedTx pulls the tx from the transactions history of the boot coordinator. found is false while the tx isn't
+// there, e.g. it wasn't forged or the boot coordinator didn't sync the batch yet.
+func getForgedTx(hezClient client.HermezClient, txID hezCommon.TxID) (forged HistoryTx, found bool, err error) {
+	forged, err = GetHistoryTx(hezClient, txID)
+	if errors.Is(err, ErrTxNotFound) {
+		return HistoryTx{}, false, nil
+	}
+	if err != nil {
+		err = fmt.Errorf("[getForgedTx] Error: %w", err)
+		return
+	}
+	return forged, true, nil
+}
@@ -0,0 +1,168 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	hezCommon "github.com/hermeznetwork/hermez-node/common"
+)
+
+var testWaitOptions = WaitOptions{
+	PollInterval: 5 * time.Millisecond,
+	DroppedAfter: 50 * time.Millisecond,
+	Timeout:      2 * time.Second,
+}
+
+func testTxID(b byte) hezCommon.TxID {
+	return hezCommon.TxID{0x02, b}
+}
+
+func TestWaitForTxForged(t *testing.T) {
+	node, hezClient := newTestNode(t)
+	txID := testTxID(1)
+	node.setPoolTx(PoolTxAPI{TxID: txID, State: hezCommon.PoolL2TxStatePending})
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		batchNum := hezCommon.BatchNum(42)
+		node.setPoolTx(PoolTxAPI{TxID: txID, State: hezCommon.PoolL2TxStateForged})
+		node.setHistoryTx(HistoryTx{TxID: txID, BatchNum: &batchNum, Timestamp: time.Unix(1600000000, 0)})
+	}()
+
+	outcome, err := WaitForTx(context.Background(), hezClient, txID, testWaitOptions)
+	if err != nil {
+		t.Fatalf("WaitForTx: %s", err)
+	}
+	if outcome.State != TxOutcomeForged || outcome.BatchNum != 42 || !outcome.Timestamp.Equal(time.Unix(1600000000, 0)) {
+		t.Fatalf("outcome: %+v", outcome)
+	}
+}
+
+func TestWaitForTxForgedOutOfPool(t *testing.T) {
+	node, hezClient := newTestNode(t)
+	txID := testTxID(2)
+	batchNum := hezCommon.BatchNum(7)
+	node.setHistoryTx(HistoryTx{TxID: txID, BatchNum: &batchNum})
+
+	outcome, err := WaitForTx(context.Background(), hezClient, txID, testWaitOptions)
+	if err != nil {
+		t.Fatalf("WaitForTx: %s", err)
+	}
+	if outcome.State != TxOutcomeForged || outcome.BatchNum != 7 {
+		t.Fatalf("outcome: %+v", outcome)
+	}
+}
+
+func TestWaitForTxInvalid(t *testing.T) {
+	node, hezClient := newTestNode(t)
+	txID := testTxID(3)
+	node.setPoolTx(PoolTxAPI{TxID: txID, State: hezCommon.PoolL2TxStateInvalid, ErrorCode: 12, ErrorType: "ErrNonceNotCurrent", Info: "nonce"})
+
+	outcome, err := WaitForTx(context.Background(), hezClient, txID, testWaitOptions)
+	if err != nil {
+		t.Fatalf("WaitForTx: %s", err)
+	}
+	if outcome.State != TxOutcomeInvalid || outcome.ErrorCode != 12 || outcome.ErrorType != "ErrNonceNotCurrent" {
+		t.Fatalf("outcome: %+v", outcome)
+	}
+}
+
+func TestWaitForTxDropped(t *testing.T) {
+	node, hezClient := newTestNode(t)
+	txID := testTxID(4)
+	node.setPoolTx(PoolTxAPI{TxID: txID, State: hezCommon.PoolL2TxStatePending})
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		node.removePoolTx(txID.String())
+	}()
+
+	start := time.Now()
+	outcome, err := WaitForTx(context.Background(), hezClient, txID, testWaitOptions)
+	if err != nil {
+		t.Fatalf("WaitForTx: %s", err)
+	}
+	if outcome.State != TxOutcomeDropped {
+		t.Fatalf("outcome: %+v", outcome)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond+testWaitOptions.DroppedAfter {
+		t.Fatalf("dropped after %s, before DroppedAfter", elapsed)
+	}
+}
+
+func TestWaitForTxTimeout(t *testing.T) {
+	node, hezClient := newTestNode(t)
+	txID := testTxID(5)
+	node.setPoolTx(PoolTxAPI{TxID: txID, State: hezCommon.PoolL2TxStatePending})
+	opts := testWaitOptions
+	opts.Timeout = 30 * time.Millisecond
+
+	outcome, err := WaitForTx(context.Background(), hezClient, txID, opts)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitForTx error: %v", err)
+	}
+	if outcome.State != TxOutcomePending {
+		t.Fatalf("outcome: %+v", outcome)
+	}
+}
+
+func TestWatchTx(t *testing.T) {
+	node, hezClient := newTestNode(t)
+	txID := testTxID(6)
+	node.setPoolTx(PoolTxAPI{TxID: txID, State: hezCommon.PoolL2TxStateInvalid})
+
+	results := WatchTx(context.Background(), hezClient, txID, testWaitOptions)
+	result, ok := <-results
+	if !ok || result.Err != nil || result.Outcome.State != TxOutcomeInvalid {
+		t.Fatalf("result: %+v - received: %t", result, ok)
+	}
+	if _, ok = <-results; ok {
+		t.Fatal("channel not closed after the result")
+	}
+}
+
+func TestWatchTxCanceled(t *testing.T) {
+	node, hezClient := newTestNode(t)
+	txID := testTxID(7)
+	node.setPoolTx(PoolTxAPI{TxID: txID, State: hezCommon.PoolL2TxStatePending})
+	opts := testWaitOptions
+	opts.Timeout = 0
+	ctx, cancel := context.WithCancel(context.Background())
+
+	results := WatchTx(ctx, hezClient, txID, opts)
+	cancel()
+	select {
+	case result := <-results:
+		if !errors.Is(result.Err, context.Canceled) || result.Outcome.State != TxOutcomePending {
+			t.Fatalf("result: %+v", result)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("WatchTx didn't stop when the context was canceled")
+	}
+	if _, ok := <-results; ok {
+		t.Fatal("channel not closed after the result")
+	}
+}
+
+func TestWaitForTxCallback(t *testing.T) {
+	node, hezClient := newTestNode(t)
+	txID := testTxID(8)
+	batchNum := hezCommon.BatchNum(3)
+	node.setHistoryTx(HistoryTx{TxID: txID, BatchNum: &batchNum})
+
+	done := make(chan TxOutcome, 1)
+	WaitForTxCallback(context.Background(), hezClient, txID, testWaitOptions, func(outcome TxOutcome, err error) {
+		if err != nil {
+			t.Errorf("callback error: %s", err)
+		}
+		done <- outcome
+	})
+	select {
+	case outcome := <-done:
+		if outcome.State != TxOutcomeForged || outcome.BatchNum != 3 {
+			t.Fatalf("outcome: %+v", outcome)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("callback not called")
+	}
+}
@@ -0,0 +1,10 @@
+package util
+
+// NextFromItem returns the fromItem of the page after the one ending at lastItemID, for pages in order ASC or DESC
+func NextFromItem(lastItemID uint64, order string) *uint64 {
+	next := lastItemID + 1
+	if order == "DESC" {
+		next = lastItemID - 1
+	}
+	return &next
+}
@@ -0,0 +1,15 @@
+package util
+
+import "testing"
+
+func TestNextFromItem(t *testing.T) {
+	if next := NextFromItem(20, "ASC"); *next != 21 {
+		t.Errorf("next ASC item: %d", *next)
+	}
+	if next := NextFromItem(20, ""); *next != 21 {
+		t.Errorf("next item in default order: %d", *next)
+	}
+	if next := NextFromItem(20, "DESC"); *next != 19 {
+		t.Errorf("next DESC item: %d", *next)
+	}
+}